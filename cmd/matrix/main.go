@@ -78,14 +78,21 @@ func main() {
 		DefaultFontColor:  config.AppConfig.Default.FontColor,
 		DefaultFontStyle:  config.AppConfig.Default.FontStyle,
 		DefaultFontType:   config.AppConfig.Default.FontType,
+		Theme:             config.AppConfig.Theme,
 		Store:             appStore,
 	})
+	for _, theme := range config.AppConfig.Themes {
+		viewCommon.RegisterTheme(viewCommon.Theme(theme))
+	}
 
 	// configure utils
 	util.SetUtilConfig(&util.UtilConfig{
-		CacheDir: config.AppConfig.Data.CacheDir,
-		FontDir:  config.AppConfig.Data.FontDir,
+		CacheDir:      config.AppConfig.Data.CacheDir,
+		FontDir:       config.AppConfig.Data.FontDir,
+		FontFallbacks: config.AppConfig.Data.FontFallbacks,
+		ImageDir:      config.AppConfig.Data.ImageDir,
 	})
+	log.Printf("Found %d fonts", len(util.ScanFonts()))
 
 	// configure server
 	api.SetAppServerConfig(&api.AppServerConfig{
@@ -1,14 +1,20 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
 	"github.com/6ixisgood/matrix-ticker/pkg/view"
 	viewCommon "github.com/6ixisgood/matrix-ticker/pkg/view/common"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"image/png"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type AppServer struct {
@@ -38,6 +44,18 @@ func InitializeRoutes() {
 	Server.router.GET("/views/definitions/:id", getViewDefinition)
 	Server.router.DELETE("/views/definitions/:id", deleteViewDefinition)
 	Server.router.GET("/views/:id", getViewById)
+	Server.router.GET("/partials", getAllPartials)
+	Server.router.POST("/partials", savePartial)
+	Server.router.GET("/partials/:name", getPartial)
+	Server.router.DELETE("/partials/:name", deletePartial)
+	Server.router.GET("/themes", getAllThemes)
+	Server.router.POST("/themes", saveTheme)
+	Server.router.GET("/themes/:name", getTheme)
+	Server.router.DELETE("/themes/:name", deleteTheme)
+	Server.router.GET("/theme", getActiveTheme)
+	Server.router.PUT("/theme", setActiveTheme)
+	Server.router.GET("/fonts", getAllFonts)
+	Server.router.GET("/fonts/:name/preview", getFontPreview)
 	Server.router.POST("/display/:id", displayViewById)
 }
 
@@ -89,6 +107,7 @@ func saveViewDefinition(c *gin.Context) {
 		Id:     body.Id,
 		Name:   body.Name,
 		Type:   body.Type,
+		Theme:  body.Theme,
 		Config: configInstance,
 	}
 
@@ -114,6 +133,175 @@ func deleteViewDefinition(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "View definition deleted successfully"})
 }
 
+func getAllPartials(c *gin.Context) {
+	partials, err := viewCommon.GetAllPartials()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error retrieving partials"})
+		return
+	}
+	c.JSON(http.StatusOK, partials)
+}
+
+func getPartial(c *gin.Context) {
+	name := c.Param("name")
+	partial, err := viewCommon.GetPartial(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Partial not found"})
+		return
+	}
+	c.JSON(http.StatusOK, partial)
+}
+
+func savePartial(c *gin.Context) {
+	var body viewCommon.Partial
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad request body"})
+		return
+	}
+
+	if err := body.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad partial passed", "error": err.Error()})
+		return
+	}
+
+	if err := viewCommon.SavePartial(body); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error saving partial"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Partial saved successfully", "name": body.Name})
+}
+
+func deletePartial(c *gin.Context) {
+	name := c.Param("name")
+
+	err := viewCommon.DeletePartial(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("Unable to delete partial: %s", name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partial deleted successfully"})
+}
+
+func getAllThemes(c *gin.Context) {
+	themes, err := viewCommon.GetAllThemes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error retrieving themes"})
+		return
+	}
+	c.JSON(http.StatusOK, themes)
+}
+
+func getTheme(c *gin.Context) {
+	name := c.Param("name")
+	theme, err := viewCommon.GetTheme(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Theme not found"})
+		return
+	}
+	c.JSON(http.StatusOK, theme)
+}
+
+func saveTheme(c *gin.Context) {
+	var body viewCommon.Theme
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad request body"})
+		return
+	}
+
+	if err := body.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad theme passed", "error": err.Error()})
+		return
+	}
+
+	if err := viewCommon.SaveTheme(body); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error saving theme"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Theme saved successfully", "name": body.Name})
+}
+
+func deleteTheme(c *gin.Context) {
+	name := c.Param("name")
+
+	err := viewCommon.DeleteTheme(name)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": fmt.Sprintf("Unable to delete theme: %s", name)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Theme deleted successfully"})
+}
+
+// getActiveTheme the theme views use unless their definition picks one,
+// filled in from the default theme
+func getActiveTheme(c *gin.Context) {
+	c.JSON(http.StatusOK, viewCommon.ResolveTheme(""))
+}
+
+// setActiveTheme switches themes globally, redrawing the current view
+func setActiveTheme(c *gin.Context) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := c.BindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad request body"})
+		return
+	}
+
+	if err := viewCommon.SetActiveTheme(body.Name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Unable to switch theme", "error": err.Error()})
+		return
+	}
+
+	view.GetAnimation().Refresh()
+	c.JSON(http.StatusOK, gin.H{"message": "Theme switched successfully", "name": body.Name})
+}
+
+// getAllFonts lists the fonts found in the font directory along with the
+// built in ones, each with a link to a preview
+func getAllFonts(c *gin.Context) {
+	type fontListing struct {
+		util.FontInfo
+		Preview string `json:"preview"`
+	}
+	listing := []fontListing{}
+	for _, info := range util.ScanFonts() {
+		listing = append(listing, fontListing{info, "/fonts/" + url.PathEscape(info.Name) + "/preview"})
+	}
+	c.JSON(http.StatusOK, listing)
+}
+
+// getFontPreview draws sample text in a font as a PNG, the text, size and
+// scale can be set with query params
+func getFontPreview(c *gin.Context) {
+	name := c.Param("name")
+	info, ok := util.FindFont(name)
+	if !ok || !strings.EqualFold(info.Name, name) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Font not found"})
+		return
+	}
+
+	text := c.DefaultQuery("text", "AaBbCc 0123 éñü ★")
+	size, err := strconv.ParseFloat(c.DefaultQuery("size", "16"), 64)
+	if err != nil || size <= 0 || size > 256 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad font size"})
+		return
+	}
+	scale, err := strconv.Atoi(c.DefaultQuery("scale", "4"))
+	if err != nil || scale < 1 || scale > 16 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Bad preview scale"})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, util.FontPreview(info.Name, text, size, scale)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Error drawing preview"})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", buf.Bytes())
+}
+
 func getAllViewConfigSpecs(c *gin.Context) {
 	configs := make(map[string]interface{})
 	for name, regView := range viewCommon.RegisteredViews {
@@ -162,6 +350,8 @@ func displayViewById(c *gin.Context) {
 		return
 	}
 
+	newView.SetTheme(viewDefinition.Theme)
+
 	log.Printf("Initializing the %s view", viewDefinition.Id)
 	animation := view.GetAnimation()
 	animation.Init(newView)
@@ -193,6 +383,7 @@ func displayView(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Failed to create view with given config", "error": err.Error()})
 		return
 	}
+	newView.SetTheme(body.Theme)
 
 	log.Printf("Initializing the %s view", body.Type)
 	animation := view.GetAnimation()
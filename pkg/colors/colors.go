@@ -0,0 +1,244 @@
+// Package colors parses the colors used throughout templates and config
+package colors
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Parse parses a color given as any of
+//
+//	#RGB, #RGBA, #RRGGBB or #RRGGBBAA
+//	a CSS color name, e.g. red or rebeccapurple, or transparent
+//	rgb(255, 0, 0), rgba(255, 0, 0, 0.5), rgb(100% 0% 0% / 50%)
+//	hsl(120, 100%, 50%), hsla(120, 100%, 50%, 0.5), hsl(120deg 100% 50% / 0.5)
+//
+// returning it with straight, not premultiplied, alpha. Theme references
+// like theme.primary are swapped for the theme's color before parsing,
+// so reaching here means one was used somewhere a theme isn't known
+func Parse(value string) (color.NRGBA, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case s == "":
+		return color.NRGBA{}, fmt.Errorf("empty color")
+	case strings.HasPrefix(s, "#"):
+		return parseHex(s[1:], value)
+	case strings.HasPrefix(s, "theme."):
+		return color.NRGBA{}, fmt.Errorf("theme color '%s' used outside of a view", value)
+	case strings.HasSuffix(s, ")"):
+		open := strings.IndexByte(s, '(')
+		if open < 0 {
+			break
+		}
+		name, args := strings.TrimSpace(s[:open]), s[open+1:len(s)-1]
+		switch name {
+		case "rgb", "rgba":
+			return parseRGB(args, value)
+		case "hsl", "hsla":
+			return parseHSL(args, value)
+		}
+	default:
+		if c, ok := names[s]; ok {
+			return c, nil
+		}
+	}
+	return color.NRGBA{}, fmt.Errorf("invalid color '%s'", value)
+}
+
+// ParseList parses a comma separated list of colors, e.g. for a gradient.
+// Commas inside rgb() and hsl() don't split the list
+func ParseList(value string) ([]color.NRGBA, error) {
+	var out []color.NRGBA
+	for _, item := range SplitList(value) {
+		c, err := Parse(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no colors in '%s'", value)
+	}
+	return out, nil
+}
+
+// SplitList splits a comma separated list of colors, leaving the commas
+// inside rgb() and hsl() alone
+func SplitList(value string) []string {
+	var out []string
+	depth, start := 0, 0
+	for i, r := range value {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	out = append(out, value[start:])
+
+	items := out[:0]
+	for _, item := range out {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Valid reports whether value parses, or is a theme reference that will
+// once it's known which theme it's in
+func Valid(value string) error {
+	if IsThemeRef(value) {
+		return nil
+	}
+	_, err := Parse(value)
+	return err
+}
+
+// IsThemeRef reports whether value refers to a theme's color, e.g.
+// theme.primary
+func IsThemeRef(value string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(value)), "theme.")
+}
+
+// Hex formats c as #RRGGBBAA
+func Hex(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.R, c.G, c.B, c.A)
+}
+
+func parseHex(digits string, value string) (color.NRGBA, error) {
+	switch len(digits) {
+	case 3, 4:
+		// each digit doubled, #F80 is #FF8800
+		long := make([]byte, 0, 8)
+		for i := range digits {
+			long = append(long, digits[i], digits[i])
+		}
+		digits = string(long)
+	case 6, 8:
+	default:
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s', expected #RGB, #RGBA, #RRGGBB or #RRGGBBAA", value)
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	n, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s', not hex", value)
+	}
+	return color.NRGBA{uint8(n >> 24), uint8(n >> 16), uint8(n >> 8), uint8(n)}, nil
+}
+
+// splitArgs splits the arguments of rgb() or hsl(), separated by commas or
+// spaces, with the alpha after a / in the space separated form
+func splitArgs(args string) []string {
+	args = strings.NewReplacer(",", " ", "/", " ").Replace(args)
+	return strings.Fields(args)
+}
+
+// parseNumber parses a number, or a percentage of max
+func parseNumber(s string, max float64) (float64, error) {
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		return v / 100 * max, err
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// parseAlpha parses an alpha from 0 to 1 or 0% to 100%, 1 if missing
+func parseAlpha(args []string, i int) (uint8, error) {
+	if len(args) <= i {
+		return 255, nil
+	}
+	a, err := parseNumber(args[i], 1)
+	return channel(a * 255), err
+}
+
+// channel clamps and rounds v to 0-255
+func channel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+func parseRGB(args string, value string) (color.NRGBA, error) {
+	parts := splitArgs(args)
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s', expected 3 or 4 values", value)
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		v, err := parseNumber(parts[i], 255)
+		if err != nil {
+			return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+		}
+		rgb[i] = v
+	}
+	a, err := parseAlpha(parts, 3)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+	}
+	return color.NRGBA{channel(rgb[0]), channel(rgb[1]), channel(rgb[2]), a}, nil
+}
+
+func parseHSL(args string, value string) (color.NRGBA, error) {
+	parts := splitArgs(args)
+	if len(parts) != 3 && len(parts) != 4 {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s', expected 3 or 4 values", value)
+	}
+	h, err := strconv.ParseFloat(strings.TrimSuffix(parts[0], "deg"), 64)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+	}
+	s, err := parseNumber(parts[1], 1)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+	}
+	l, err := parseNumber(parts[2], 1)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+	}
+	a, err := parseAlpha(parts, 3)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color '%s': %v", value, err)
+	}
+	r, g, b := HSL(h, s, l)
+	return color.NRGBA{r, g, b, a}, nil
+}
+
+// HSL converts a hue in degrees and saturation and lightness from 0 to 1
+// to RGB
+func HSL(h float64, s float64, l float64) (uint8, uint8, uint8) {
+	h = math.Mod(math.Mod(h, 360)+360, 360) / 360
+	s, l = math.Max(0, math.Min(1, s)), math.Max(0, math.Min(1, l))
+	if s == 0 {
+		return channel(l * 255), channel(l * 255), channel(l * 255)
+	}
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+	hue := func(t float64) float64 {
+		t = math.Mod(t+1, 1)
+		switch {
+		case t < 1.0/6:
+			return p + (q-p)*6*t
+		case t < 1.0/2:
+			return q
+		case t < 2.0/3:
+			return p + (q-p)*(2.0/3-t)*6
+		}
+		return p
+	}
+	return channel(hue(h+1.0/3) * 255), channel(hue(h) * 255), channel(hue(h-1.0/3) * 255)
+}
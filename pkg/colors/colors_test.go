@@ -0,0 +1,53 @@
+package colors
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for value, want := range map[string]color.NRGBA{
+		"#F80":                     {255, 136, 0, 255},
+		"#F808":                    {255, 136, 0, 136},
+		"#66CCFF":                  {102, 204, 255, 255},
+		"#66ccff80":                {102, 204, 255, 128},
+		" Red ":                    {255, 0, 0, 255},
+		"rebeccapurple":            {102, 51, 153, 255},
+		"transparent":              {0, 0, 0, 0},
+		"rgb(255, 128, 0)":         {255, 128, 0, 255},
+		"rgba(255,128,0,0.5)":      {255, 128, 0, 128},
+		"rgb(100% 50% 0% / 25%)":   {255, 128, 0, 64},
+		"rgb(300, -5, 0)":          {255, 0, 0, 255},
+		"hsl(0, 100%, 50%)":        {255, 0, 0, 255},
+		"hsl(120deg 100% 25%)":     {0, 128, 0, 255},
+		"hsla(240, 100%, 50%, .5)": {0, 0, 255, 128},
+		"hsl(-120, 0%, 100%)":      {255, 255, 255, 255},
+	} {
+		c, err := Parse(value)
+		if assert.NoError(t, err, value) {
+			assert.Equal(t, want, c, value)
+		}
+	}
+
+	for _, value := range []string{"", "#00000FF", "#GGGGGG", "notacolor", "rgb(1, 2)", "hsl(a, 1%, 1%)", "theme.primary", "rgb(1,2,3"} {
+		_, err := Parse(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestParseList(t *testing.T) {
+	list, err := ParseList("red, rgb(0, 255, 0),#00F,")
+	assert.NoError(t, err)
+	assert.Equal(t, []color.NRGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}}, list)
+
+	_, err = ParseList("red, nope")
+	assert.Error(t, err)
+	_, err = ParseList(" , ")
+	assert.Error(t, err)
+
+	assert.NoError(t, Valid("theme.primary"))
+	assert.Error(t, Valid("#12"))
+	assert.Equal(t, "#FF880080", Hex(color.NRGBA{255, 136, 0, 128}))
+}
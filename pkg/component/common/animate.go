@@ -0,0 +1,135 @@
+package common
+
+import (
+	"encoding/xml"
+	"image/color"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+)
+
+// Animation moves one attribute of a component between two values over
+// time. It's declared as an <animate> child of the component, e.g.
+//
+//	<animate attr="pos-x" from="0" to="32" dur="2s" easing="ease-in-out" repeat="indefinite"/>
+//
+// Animations are applied when the parent template draws the component, so
+// they work on any component without it knowing about them
+type Animation struct {
+	XMLName   xml.Name `xml:"animate"`
+	Attr      string   `xml:"attr,attr"` // pos-x, pos-y, opacity, scale or color
+	From      string   `xml:"from,attr"`
+	To        string   `xml:"to,attr"`
+	Dur       string   `xml:"dur,attr"`       // length of one run, e.g. "2s"
+	Begin     string   `xml:"begin,attr"`     // delay before the first run
+	Easing    string   `xml:"easing,attr"`    // see Easing for names
+	Repeat    string   `xml:"repeat,attr"`    // number of runs, or "indefinite"
+	Alternate bool     `xml:"alternate,attr"` // run backwards every other time
+
+	from, to   float64
+	fromColor  color.RGBA
+	toColor    color.RGBA
+	dur, begin time.Duration
+	repeat     float64
+	ease       EasingFunc
+	start      time.Time
+}
+
+// Init parses the animation's values. Its clock starts on the first frame
+// it's drawn
+func (a *Animation) Init() {
+	a.dur = ParseDuration(a.Dur, time.Second)
+	a.begin = ParseDuration(a.Begin, 0)
+	a.ease = Easing(a.Easing)
+
+	switch a.Repeat {
+	case "", "1":
+		a.repeat = 1
+	case "indefinite":
+		a.repeat = math.Inf(1)
+	default:
+		var err error
+		a.repeat, err = strconv.ParseFloat(a.Repeat, 64)
+		if err != nil || a.repeat <= 0 {
+			log.Printf("Invalid animation repeat '%s', running once", a.Repeat)
+			a.repeat = 1
+		}
+	}
+
+	if a.Attr == "color" {
+		a.fromColor = parseAnimationColor(a.From)
+		a.toColor = parseAnimationColor(a.To)
+	} else {
+		a.from = parseAnimationFloat(a.From)
+		a.to = parseAnimationFloat(a.To)
+	}
+}
+
+func parseAnimationFloat(value string) float64 {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid animation value '%s'", value)
+	}
+	return v
+}
+
+func parseAnimationColor(value string) color.RGBA {
+	var c util.RGBA
+	if err := c.UnmarshalXMLAttr(xml.Attr{Value: value}); err != nil {
+		log.Printf("Invalid animation color '%s'", value)
+	}
+	return c.RGBA
+}
+
+// progress returns how far through the animation we are after elapsed,
+// with easing applied
+func (a *Animation) progress(elapsed time.Duration) float64 {
+	if elapsed < a.begin || a.dur <= 0 {
+		return 0
+	}
+	runs := float64(elapsed-a.begin) / float64(a.dur)
+	run, p := math.Floor(runs), runs-math.Floor(runs)
+	if runs >= a.repeat {
+		// hold the final value
+		run, p = math.Ceil(a.repeat)-1, a.repeat-(math.Ceil(a.repeat)-1)
+	}
+	if a.Alternate && int(run)%2 == 1 {
+		p = 1 - p
+	}
+	return a.ease(p)
+}
+
+// Animate returns the state of animations after their clocks have run
+// for the given time
+func Animate(animations []*Animation, now time.Time) DrawState {
+	state := DrawState{Opacity: 1, Scale: 1}
+	for _, a := range animations {
+		if a.start.IsZero() {
+			a.start = now
+		}
+		p := a.progress(now.Sub(a.start))
+		v := a.from + (a.to-a.from)*p
+		switch a.Attr {
+		case "pos-x":
+			state.Offset.X = int(math.Round(v))
+		case "pos-y":
+			state.Offset.Y = int(math.Round(v))
+		case "opacity":
+			state.Opacity = math.Max(0, math.Min(1, v))
+		case "scale":
+			state.Scale = math.Max(0, v)
+		case "color":
+			lerp := func(a, b uint8) uint8 { return uint8(float64(a) + (float64(b)-float64(a))*p) }
+			state.Tint = &color.RGBA{
+				lerp(a.fromColor.R, a.toColor.R),
+				lerp(a.fromColor.G, a.toColor.G),
+				lerp(a.fromColor.B, a.toColor.B),
+				lerp(a.fromColor.A, a.toColor.A),
+			}
+		}
+	}
+	return state
+}
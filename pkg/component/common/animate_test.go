@@ -0,0 +1,36 @@
+package common
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimateRepeatAndAlternate(t *testing.T) {
+	a := &Animation{Attr: "pos-x", From: "0", To: "10", Dur: "1s", Repeat: "indefinite", Alternate: true}
+	a.Init()
+
+	start := time.Unix(0, 0)
+	at := func(d time.Duration) DrawState {
+		return Animate([]*Animation{a}, start.Add(d))
+	}
+	assert.Equal(t, image.Pt(0, 0), at(0).Offset)
+	assert.Equal(t, image.Pt(5, 0), at(500*time.Millisecond).Offset)
+	// running backwards on the second pass
+	assert.Equal(t, image.Pt(8, 0), at(1200*time.Millisecond).Offset)
+	assert.Equal(t, image.Pt(2, 0), at(1800*time.Millisecond).Offset)
+}
+
+func TestAnimateHoldsFinalValue(t *testing.T) {
+	a := &Animation{Attr: "opacity", From: "1", To: "0", Dur: "1s", Begin: "1s", Easing: "ease-in-out"}
+	a.Init()
+
+	// the clock starts on the first frame
+	start := time.Unix(0, 0)
+	assert.Equal(t, 1.0, Animate([]*Animation{a}, start).Opacity)
+	assert.Equal(t, 1.0, Animate([]*Animation{a}, start.Add(500*time.Millisecond)).Opacity)
+	assert.Equal(t, 0.5, Animate([]*Animation{a}, start.Add(1500*time.Millisecond)).Opacity)
+	assert.Equal(t, 0.0, Animate([]*Animation{a}, start.Add(5*time.Second)).Opacity)
+}
@@ -3,6 +3,8 @@ package common
 import (
 	"github.com/fogleman/gg"
 	"image"
+	"log"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -18,10 +20,18 @@ type BaseComponent struct {
 	PosX          int `xml:"pos-x,attr"`
 	PosY          int `xml:"pos-y,attr"`
 
-	Ctx     *gg.Context
-	prevImg image.Image
-	Ticker  *time.Ticker
-	Rr      int // render rate in milliseconds
+	Opacity string       `xml:"opacity,attr"` // 0 to 1
+	Blend   string       `xml:"blend,attr"`   // normal, add, multiply or screen
+	Animate []*Animation `xml:"animate"`
+
+	opacity float64
+	source  Source
+
+	Ctx        *gg.Context
+	prevImg    image.Image
+	Rr         int // render rate in milliseconds
+	rate       time.Duration
+	lastRender time.Time
 }
 
 func (bc *BaseComponent) Init() {
@@ -40,6 +50,19 @@ func (bc *BaseComponent) Init() {
 		bc.ComputedSizeY, _ = strconv.Atoi(bc.SizeY)
 	}
 
+	bc.opacity = 1
+	if bc.Opacity != "" {
+		o, err := strconv.ParseFloat(bc.Opacity, 64)
+		if err != nil {
+			log.Printf("Invalid opacity '%s'", bc.Opacity)
+		} else {
+			bc.opacity = math.Max(0, math.Min(1, o))
+		}
+	}
+	for _, a := range bc.Animate {
+		a.Init()
+	}
+
 	// create a context, if needed
 	if bc.Ctx == nil && bc.ComputedSizeX > 0 && bc.ComputedSizeY > 0 {
 		bc.Ctx = gg.NewContext(bc.ComputedSizeX, bc.ComputedSizeY)
@@ -48,11 +71,23 @@ func (bc *BaseComponent) Init() {
 	if bc.Rr == 0 {
 		bc.Rr = 5
 	}
+	bc.SetRate(time.Duration(bc.Rr) * time.Millisecond)
+}
+
+// SetRate sets how often the component re-renders, zero or less for
+// components that never change once rendered
+func (bc *BaseComponent) SetRate(rate time.Duration) {
+	bc.rate = rate
+}
 
-	// set ticker for render rate
-	if bc.Rr > 0 {
-		bc.Ticker = time.NewTicker(time.Duration(bc.Rr) * time.Millisecond)
+// Due reports whether the component should re-render for a frame shown
+// at now, or can reuse the image it rendered last
+func (bc *BaseComponent) Due(now time.Time) bool {
+	if bc.rate <= 0 || now.Sub(bc.lastRender) < bc.rate {
+		return false
 	}
+	bc.lastRender = now
+	return true
 }
 
 func (bc *BaseComponent) Width() int {
@@ -72,18 +107,28 @@ func (bc *BaseComponent) SetParentSize(width int, height int) {
 	bc.ParentHeight = height
 }
 
-func (bc *BaseComponent) TickerChan() <-chan time.Time {
-	if bc.Ticker != nil {
-		return bc.Ticker.C
-	} else {
-		return nil
-	}
+func (bc *BaseComponent) ParentSize() (int, int) {
+	return bc.ParentWidth, bc.ParentHeight
 }
 
-func (bc *BaseComponent) Stop() {
-	if bc.Ticker != nil {
-		bc.Ticker.Stop()
-	}
+func (bc *BaseComponent) Stop() {}
+
+// DrawState returns how the component should be drawn onto its parent at
+// the given time
+func (bc *BaseComponent) DrawState(now time.Time) DrawState {
+	state := Animate(bc.Animate, now)
+	state.Pos = image.Pt(bc.PosX, bc.PosY)
+	state.Opacity *= bc.opacity
+	state.Blend = bc.Blend
+	return state
+}
+
+func (bc *BaseComponent) Source() Source {
+	return bc.source
+}
+
+func (bc *BaseComponent) SetSource(source Source) {
+	bc.source = source
 }
 
 func (bc *BaseComponent) PrevImg() image.Image {
@@ -6,15 +6,19 @@ import (
 )
 
 type Component interface {
-	Init()                               // Ran before componenet is rendered
-	Render() image.Image                 // Render the component to an image.Image representation
-	Width() int                          // Return the width of the component. Used to help position components on the display
-	Height() int                         // Return the height of the compoent. Used to help position components on the display
-	PrevImg() image.Image                // return the previously rendered image
-	SetPrevImg(img image.Image)          // the the previously rendered iamge
-	TickerChan() <-chan time.Time        // return the channel for the ticker
-	Stop()                               // Stop the ticker
-	SetParentSize(width int, height int) // set the parent's size to use in calculations
+	Init()                                 // Ran before componenet is rendered
+	Render(ctx *RenderContext) image.Image // Render the component to an image.Image representation
+	Width() int                            // Return the width of the component. Used to help position components on the display
+	Height() int                           // Return the height of the compoent. Used to help position components on the display
+	PrevImg() image.Image                  // return the previously rendered image
+	SetPrevImg(img image.Image)            // the the previously rendered iamge
+	Due(now time.Time) bool                // whether the component needs to re-render for a frame shown at now
+	Stop()                                 // Stop anything the component has running
+	SetParentSize(width int, height int)   // set the parent's size to use in calculations
+	ParentSize() (int, int)                // the parent's size the component was sized against
+	DrawState(now time.Time) DrawState     // how to draw the component onto its parent, with animations applied
+	Source() Source                        // the markup the component was parsed from
+	SetSource(source Source)               // set the markup the component was parsed from
 }
 
 type ComponentContext struct {
@@ -0,0 +1,136 @@
+package common
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
+	"github.com/nfnt/resize"
+)
+
+// DrawState how a component's image is drawn onto its parent, combining
+// the component's own opacity and blend mode with any animations
+type DrawState struct {
+	Pos     image.Point // requested position, used when the parent stacks its children
+	Offset  image.Point
+	Opacity float64
+	Scale   float64
+	Tint    *color.RGBA
+	Blend   string // normal, add, multiply or screen
+}
+
+// ParseBgColor parses a background color, anything colors.Parse accepts,
+// where the alpha is straight rather than premultiplied. Invalid colors
+// are logged and fall back to opaque black, as does an empty one
+func ParseBgColor(value string) color.Color {
+	if value == "" {
+		return color.NRGBA{0, 0, 0, 255}
+	}
+	c, err := colors.Parse(value)
+	if err != nil {
+		log.Printf("Invalid background color, using black: %v", err)
+		return color.NRGBA{0, 0, 0, 255}
+	}
+	return c
+}
+
+// Composite draws im onto dst at the given point with the draw state
+// applied. Scaling happens around the image's center and the tint
+// multiplies each pixel's color
+func Composite(dst draw.Image, im image.Image, at image.Point, state DrawState) {
+	if state.Opacity <= 0 || state.Scale <= 0 {
+		return
+	}
+
+	if state.Tint != nil {
+		im = tint(im, *state.Tint)
+	}
+
+	if state.Scale != 1 {
+		b := im.Bounds()
+		w := uint(math.Round(float64(b.Dx()) * state.Scale))
+		h := uint(math.Round(float64(b.Dy()) * state.Scale))
+		if w == 0 || h == 0 {
+			return
+		}
+		at = at.Add(image.Pt((b.Dx()-int(w))/2, (b.Dy()-int(h))/2))
+		im = resize.Resize(w, h, im, resize.NearestNeighbor)
+	}
+
+	at = at.Add(state.Offset)
+	r := im.Bounds().Sub(im.Bounds().Min).Add(at)
+
+	if blend, ok := blendModes[state.Blend]; ok {
+		if rgba, isRGBA := dst.(*image.RGBA); isRGBA {
+			blendOnto(rgba, im, r, state.Opacity, blend)
+			return
+		}
+	}
+
+	var mask image.Image
+	if state.Opacity < 1 {
+		mask = image.NewUniform(color.Alpha{uint8(state.Opacity * 255)})
+	}
+	draw.DrawMask(dst, r, im, im.Bounds().Min, mask, image.Point{}, draw.Over)
+}
+
+// blendFunc combines one premultiplied source channel with the destination
+// channel underneath it. Values and alphas run from 0 to 1
+type blendFunc func(s, d, sa, da float64) float64
+
+var blendModes = map[string]blendFunc{
+	"add": func(s, d, sa, da float64) float64 {
+		return s + d
+	},
+	"multiply": func(s, d, sa, da float64) float64 {
+		return s*(1-da) + d*(1-sa) + s*d
+	},
+	"screen": func(s, d, sa, da float64) float64 {
+		return s + d - s*d
+	},
+}
+
+// blendOnto draws src over the rectangle r of dst using a blend mode
+func blendOnto(dst *image.RGBA, src image.Image, r image.Rectangle, opacity float64, blend blendFunc) {
+	clip := r.Intersect(dst.Bounds())
+	offset := src.Bounds().Min.Sub(r.Min)
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		for x := clip.Min.X; x < clip.Max.X; x++ {
+			sr, sg, sb, sa := src.At(x+offset.X, y+offset.Y).RGBA()
+			if sa == 0 {
+				continue
+			}
+			s := [4]float64{float64(sr), float64(sg), float64(sb), float64(sa)}
+			i := dst.PixOffset(x, y)
+			d := dst.Pix[i : i+4 : i+4]
+			da := float64(d[3]) / 255
+			alpha := s[3] / 0xffff * opacity
+			for c := 0; c < 3; c++ {
+				v := blend(s[c]/0xffff*opacity, float64(d[c])/255, alpha, da)
+				d[c] = uint8(math.Round(255 * math.Max(0, math.Min(v, 1))))
+			}
+			d[3] = uint8(math.Round(255 * math.Min(blend(alpha, da, alpha, da), 1)))
+		}
+	}
+}
+
+// tint returns a copy of im with every pixel multiplied by c
+func tint(im image.Image, c color.RGBA) image.Image {
+	b := im.Bounds()
+	out := image.NewRGBA(b)
+	draw.Draw(out, b, im, b.Min, draw.Src)
+	// pixels are premultiplied, so the tint's alpha scales every channel
+	scale := func(v uint8, by uint8) uint8 {
+		return uint8(uint32(v) * uint32(by) * uint32(c.A) / (255 * 255))
+	}
+	for i := 0; i < len(out.Pix); i += 4 {
+		out.Pix[i] = scale(out.Pix[i], c.R)
+		out.Pix[i+1] = scale(out.Pix[i+1], c.G)
+		out.Pix[i+2] = scale(out.Pix[i+2], c.B)
+		out.Pix[i+3] = scale(out.Pix[i+3], 255)
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package common
+
+import (
+	"log"
+	"math"
+)
+
+// EasingFunc maps progress through an animation, from 0 to 1, to how far
+// the animated value has moved
+type EasingFunc func(float64) float64
+
+var easings = map[string]EasingFunc{
+	"linear":        func(t float64) float64 { return t },
+	"ease-in":       func(t float64) float64 { return t * t * t },
+	"ease-out":      func(t float64) float64 { return 1 - math.Pow(1-t, 3) },
+	"ease-in-out":   easeInOutCubic,
+	"ease-in-quad":  func(t float64) float64 { return t * t },
+	"ease-out-quad": func(t float64) float64 { return 1 - (1-t)*(1-t) },
+	"ease-in-sine":  func(t float64) float64 { return 1 - math.Cos(t*math.Pi/2) },
+	"ease-out-sine": func(t float64) float64 { return math.Sin(t * math.Pi / 2) },
+	"ease-in-out-sine": func(t float64) float64 {
+		return -(math.Cos(math.Pi*t) - 1) / 2
+	},
+	"ease-in-back": func(t float64) float64 {
+		const c1 = 1.70158
+		return (c1+1)*t*t*t - c1*t*t
+	},
+	"ease-out-back": func(t float64) float64 {
+		const c1 = 1.70158
+		return 1 + (c1+1)*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+	},
+	"ease-out-bounce": easeOutBounce,
+	"ease-in-bounce":  func(t float64) float64 { return 1 - easeOutBounce(1-t) },
+	"ease-out-elastic": func(t float64) float64 {
+		if t == 0 || t == 1 {
+			return t
+		}
+		return math.Pow(2, -10*t)*math.Sin((t*10-0.75)*2*math.Pi/3) + 1
+	},
+	"step-start": func(t float64) float64 { return math.Ceil(t) },
+	"step-end":   func(t float64) float64 { return math.Floor(t) },
+}
+
+func easeInOutCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+func easeOutBounce(t float64) float64 {
+	const n1, d1 = 7.5625, 2.75
+	switch {
+	case t < 1/d1:
+		return n1 * t * t
+	case t < 2/d1:
+		t -= 1.5 / d1
+		return n1*t*t + 0.75
+	case t < 2.5/d1:
+		t -= 2.25 / d1
+		return n1*t*t + 0.9375
+	default:
+		t -= 2.625 / d1
+		return n1*t*t + 0.984375
+	}
+}
+
+// Easing looks up an easing function by name, falling back to linear
+// when the name is empty or unknown
+func Easing(name string) EasingFunc {
+	if name == "" {
+		return easings["linear"]
+	}
+	ease, ok := easings[name]
+	if !ok {
+		log.Printf("Unknown easing '%s', using linear", name)
+		return easings["linear"]
+	}
+	return ease
+}
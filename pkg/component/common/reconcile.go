@@ -0,0 +1,165 @@
+package common
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// RenderMu held while a template is drawn, and while the template replacing
+// it takes over its running components. Initializing the new template
+// happens outside of it, so the running one carries on being drawn meanwhile
+var RenderMu sync.Mutex
+
+// Source the markup a component was parsed from, used to tell which parts
+// of a refreshed template changed
+type Source struct {
+	Tag    string // element name and attributes
+	Markup string // the whole element, children included
+}
+
+// Reconciler is implemented by components that can take over from the
+// running component they replace, keeping things like how far they've
+// scrolled. Reconcile is called before Init, holding RenderMu, and is
+// responsible for stopping whatever it doesn't carry over from prev
+type Reconciler interface {
+	Reconcile(prev Component)
+}
+
+// tagKey identifies an element by its name and attributes
+func tagKey(start xml.StartElement) string {
+	return fmt.Sprintf("%s %v", start.Name.Local, start.Attr)
+}
+
+// captureElement reads the rest of the element opened by start and
+// returns it re-encoded, so it can be both compared and decoded
+func captureElement(d *xml.Decoder, start xml.StartElement) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeToken(start); err != nil {
+		return nil, err
+	}
+	for depth := 1; depth > 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			continue
+		}
+		if err := enc.EncodeToken(xml.CopyToken(tok)); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeComponent creates the component start names and decodes markup,
+// the whole element, into it
+func decodeComponent(start xml.StartElement, markup []byte) (Component, error) {
+	create, ok := RegisteredComponents[start.Name.Local]
+	if !ok {
+		return nil, fmt.Errorf("invalid component type %s", start.Name.Local)
+	}
+	c := create()
+	if err := xml.Unmarshal(markup, c); err != nil {
+		return nil, err
+	}
+	c.SetSource(Source{Tag: tagKey(start), Markup: string(markup)})
+	return c, nil
+}
+
+// rebuild decodes a new, uninitialized copy of a kept component that has to
+// be sized again, letting it take over from the running one like a changed
+// component would. False is returned if it couldn't be, leaving kept as is
+func rebuild(kept Component) (Component, bool) {
+	source := kept.Source()
+	tok, err := xml.NewDecoder(strings.NewReader(source.Markup)).Token()
+	start, ok := tok.(xml.StartElement)
+	if err != nil || !ok {
+		log.Printf("Unable to rebuild component from '%s'", source.Markup)
+		return kept, false
+	}
+	c, err := decodeComponent(start, []byte(source.Markup))
+	if err != nil {
+		log.Printf("Unable to rebuild component from '%s': %v", source.Markup, err)
+		return kept, false
+	}
+	c.SetSource(source)
+	// kept may still be being drawn by the running template
+	RenderMu.Lock()
+	defer RenderMu.Unlock()
+	if r, ok := c.(Reconciler); ok {
+		r.Reconcile(kept)
+	} else {
+		kept.Stop()
+	}
+	return c, true
+}
+
+// Reconcile carries running components over from prev into this not yet
+// initialized template. Children with the same markup are kept as they
+// are and skipped by Init, unless the template's size changed and they
+// have to be sized again. Changed children with the same tag get the
+// chance to take over from the one they replace. The rest of prev is
+// stopped
+func (t *Template) Reconcile(prev Component) {
+	p, ok := prev.(*Template)
+	if !ok || p.Source().Tag != t.Source().Tag {
+		prev.Stop()
+		return
+	}
+
+	t.kept = make([]bool, len(t.Components))
+	used := make([]bool, len(p.Components))
+	find := func(i int, match func(Component) bool) int {
+		// prefer the same spot, then the first one free
+		if i < len(p.Components) && !used[i] && match(p.Components[i]) {
+			return i
+		}
+		for j, c := range p.Components {
+			if !used[j] && match(c) {
+				return j
+			}
+		}
+		return -1
+	}
+
+	for i, c := range t.Components {
+		markup := c.Source().Markup
+		if j := find(i, func(o Component) bool { return o.Source().Markup == markup }); j >= 0 {
+			t.Components[i] = p.Components[j]
+			t.kept[i] = true
+			used[j] = true
+		}
+	}
+	for i, c := range t.Components {
+		r, ok := c.(Reconciler)
+		if t.kept[i] || !ok {
+			continue
+		}
+		tag := c.Source().Tag
+		if j := find(i, func(o Component) bool { return o.Source().Tag == tag }); j >= 0 {
+			used[j] = true
+			r.Reconcile(p.Components[j])
+		}
+	}
+
+	for j, c := range p.Components {
+		if !used[j] {
+			c.Stop()
+		}
+	}
+	p.BaseComponent.Stop()
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"encoding/xml"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// probe a component that counts how often it's initialized and stopped
+type probe struct {
+	BaseComponent
+	Label   string `xml:"label,attr"`
+	inits   int
+	stopped bool
+}
+
+func (p *probe) Init() {
+	p.Rr = -1
+	p.BaseComponent.Init()
+	p.inits++
+}
+
+func (p *probe) Render(ctx *RenderContext) image.Image { return image.NewRGBA(image.Rect(0, 0, 1, 1)) }
+
+func (p *probe) Stop() { p.stopped = true }
+
+func init() {
+	RegisterComponent("probe", func() Component { return &probe{} })
+}
+
+func parseTemplate(t *testing.T, src string) *Template {
+	tmpl := &Template{}
+	require.NoError(t, xml.Unmarshal([]byte(src), tmpl))
+	return tmpl
+}
+
+func TestReconcile(t *testing.T) {
+	prev := parseTemplate(t, `<template size-x="10" size-y="10">
+		<probe label="a"/>
+		<template size-x="5" size-y="5"><probe label="b"/><probe label="c"/></template>
+		<probe label="d"/>
+	</template>`)
+	prev.Init()
+	a, inner, d := prev.Components[0].(*probe), prev.Components[1].(*Template), prev.Components[2].(*probe)
+	b, c := inner.Components[0].(*probe), inner.Components[1].(*probe)
+
+	// "c" changes inside the nested template and "d" is removed
+	next := parseTemplate(t, `<template size-x="10" size-y="10">
+		<!-- comments don't count as changes -->
+		<probe label="a"/>
+		<template size-x="5" size-y="5"><probe label="b"/><probe label="c2"/></template>
+	</template>`)
+	next.Reconcile(prev)
+	next.Init()
+
+	assert.Same(t, a, next.Components[0])
+	assert.Equal(t, 1, a.inits)
+	nextInner := next.Components[1].(*Template)
+	assert.NotSame(t, inner, nextInner)
+	assert.Same(t, b, nextInner.Components[0])
+	assert.Equal(t, 1, b.inits)
+	assert.Equal(t, "c2", nextInner.Components[1].(*probe).Label)
+	assert.Equal(t, 1, nextInner.Components[1].(*probe).inits)
+
+	assert.False(t, a.stopped)
+	assert.False(t, b.stopped)
+	assert.True(t, c.stopped)
+	assert.True(t, d.stopped)
+
+	// a different root starts over
+	other := parseTemplate(t, `<template size-x="20" size-y="10"><probe label="a"/></template>`)
+	other.Reconcile(next)
+	other.Init()
+	assert.NotSame(t, a, other.Components[0])
+	assert.True(t, a.stopped)
+}
+
+func TestReconcileResized(t *testing.T) {
+	src := `<template size-x="100%" size-y="100%"><probe label="a"/></template>`
+	prev := parseTemplate(t, src)
+	prev.SetParentSize(10, 10)
+	prev.Init()
+	a := prev.Components[0].(*probe)
+
+	// the same size keeps it
+	same := parseTemplate(t, src)
+	same.SetParentSize(10, 10)
+	same.Reconcile(prev)
+	same.Init()
+	assert.Same(t, a, same.Components[0])
+	assert.Equal(t, 1, a.inits)
+
+	// a bigger parent sizes it again from scratch
+	bigger := parseTemplate(t, src)
+	bigger.SetParentSize(20, 10)
+	bigger.Reconcile(same)
+	bigger.Init()
+	resized := bigger.Components[0].(*probe)
+	assert.NotSame(t, a, resized)
+	assert.True(t, a.stopped)
+	assert.Equal(t, "a", resized.Label)
+	assert.Equal(t, 1, resized.inits)
+	w, h := resized.ParentSize()
+	assert.Equal(t, []int{20, 10}, []int{w, h})
+	assert.Equal(t, a.Source(), resized.Source())
+}
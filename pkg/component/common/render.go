@@ -0,0 +1,48 @@
+package common
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RenderContext what a component needs to know about the frame being
+// rendered. Components animate off Now and draw randomness from Rand,
+// never the wall clock or the global rand, so a template renders the same
+// way every time it's given the same frames
+type RenderContext struct {
+	Frame   int           // how many frames came before this one
+	Now     time.Time     // the time this frame is shown
+	Elapsed time.Duration // since the first frame
+	Rand    *rand.Rand
+}
+
+// Clock hands out the RenderContext for each frame. Live displays step it
+// with the wall clock, tests step it to fixed times
+type Clock struct {
+	start time.Time
+	frame int
+	rand  *rand.Rand
+}
+
+// NewClock creates a clock whose first frame is at start, with randomness
+// seeded by seed
+func NewClock(start time.Time, seed int64) *Clock {
+	return &Clock{start: start, rand: rand.New(rand.NewSource(seed))}
+}
+
+// Frame returns the context for a frame shown at now
+func (c *Clock) Frame(now time.Time) *RenderContext {
+	ctx := &RenderContext{
+		Frame:   c.frame,
+		Now:     now,
+		Elapsed: now.Sub(c.start),
+		Rand:    c.rand,
+	}
+	c.frame++
+	return ctx
+}
+
+// At returns the context for a frame shown elapsed after the first one
+func (c *Clock) At(elapsed time.Duration) *RenderContext {
+	return c.Frame(c.start.Add(elapsed))
+}
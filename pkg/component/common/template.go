@@ -2,11 +2,14 @@ package common
 
 import (
 	"encoding/xml"
-	"fmt"
 	"github.com/fogleman/gg"
 	"image"
 	"image/color"
+	"image/draw"
+	"log"
 	"math"
+	"strconv"
+	"time"
 )
 
 type Template struct {
@@ -16,28 +19,73 @@ type Template struct {
 
 	Align      string      `xml:"align,attr"`
 	Justify    string      `xml:"justify,attr"`
-	Direction  string      `xml:"dir,attr"`
+	Direction  string      `xml:"dir,attr"` // row, col or stack
 	BgColor    string      `xml:"bg-color,attr"`
+	Duration   string      `xml:"duration,attr"` // how long to show this page inside a carousel
 	Components []Component `xml:",any"`
+
+	childBounds []image.Rectangle
+	bg          color.Color
+	kept        []bool // children carried over from the running template, already initialized
 }
 
 func (t *Template) Init() {
 	t.Rr = -1
 	t.BaseComponent.Init()
-	for _, c := range t.Components {
+	for i, c := range t.Components {
+		if i < len(t.kept) && t.kept[i] {
+			if w, h := c.ParentSize(); w == t.ComputedSizeX && h == t.ComputedSizeY {
+				continue
+			}
+			// sized for a different parent, start over from its markup
+			rebuilt, ok := rebuild(c)
+			if !ok {
+				continue
+			}
+			c = rebuilt
+			t.Components[i] = c
+		}
 		c.SetParentSize(t.ComputedSizeX, t.ComputedSizeY) // Set parent size on each child component
 		c.Init()
 	}
+	t.kept = nil
 
 	if t.BgColor == "" {
 		t.BgColor = "#000000FF"
 	}
+	t.bg = ParseBgColor(t.BgColor)
 
 	// create context with sizes
 	ctxTmp := gg.NewContext(t.ComputedSizeX, t.ComputedSizeY)
 	t.Ctx = ctxTmp
 }
 
+// Resize changes the template's size once it's initialized, for containers
+// that can only size it after seeing how big its children turned out.
+// Children sized against the old size, e.g. with a percent, are sized again
+func (t *Template) Resize(width int, height int) {
+	t.ComputedSizeX, t.ComputedSizeY = width, height
+	t.Ctx = gg.NewContext(width, height)
+	for i, c := range t.Components {
+		if w, h := c.ParentSize(); w == width && h == height {
+			continue
+		}
+		rebuilt, ok := rebuild(c)
+		if !ok {
+			continue
+		}
+		t.Components[i] = rebuilt
+		rebuilt.SetParentSize(width, height)
+		rebuilt.Init()
+	}
+}
+
+// Due always, a template is only as up to date as its children, which each
+// decide for themselves whether to render again or reuse their last image
+func (t *Template) Due(now time.Time) bool {
+	return true
+}
+
 func (t *Template) Ready() bool {
 	return t.Ctx != nil
 }
@@ -77,10 +125,8 @@ type Axis struct {
 	Space        int
 }
 
-func (t *Template) Render() image.Image {
-	var r, g, b, a uint8
-	fmt.Sscanf(t.BgColor, "#%02x%02x%02x%02x", &r, &g, &b, &a)
-	t.Ctx.SetColor(color.RGBA{r, g, b, a})
+func (t *Template) Render(ctx *RenderContext) image.Image {
+	t.Ctx.SetColor(t.bg)
 	t.Ctx.Clear()
 
 	var componentLengthX, componentLengthY int
@@ -88,18 +134,10 @@ func (t *Template) Render() image.Image {
 	var cIm image.Image
 	var imList []image.Image
 	for _, c := range t.Components {
-		// chan to check if we should re-render or just grab last image
-		select {
-		case <-c.TickerChan():
-			// Ticker has ticked
-			cIm = c.Render()
-		default:
-			// Ticker has not ticked
-			cIm = c.PrevImg()
-			// check for nil
-			if cIm == nil {
-				cIm = c.Render()
-			}
+		// re-render if it's time to, otherwise grab the last image
+		cIm = c.PrevImg()
+		if c.Due(ctx.Now) || cIm == nil {
+			cIm = c.Render(ctx)
 		}
 
 		// save the prev image for next time
@@ -124,19 +162,32 @@ func (t *Template) Render() image.Image {
 	// Modularized positioning logic
 	primary.Position, primary.Space = t.computePositionAndSpace(primary, len(imList), t.Justify)
 	secondary.Position, _ = t.computePositionAndSpace(secondary, len(imList), t.Align)
-	for _, im := range imList {
+	t.childBounds = t.childBounds[:0]
+	dst := t.Ctx.Image().(draw.Image)
+	for i, im := range imList {
+		state := t.Components[i].DrawState(ctx.Now)
 		bounds := im.Bounds()
-		if t.Direction == "col" {
+		var at image.Point
+		if t.Direction == "stack" {
+			// layer children on top of each other, each placed on its own
+			x, _ := t.computePositionAndSpace(Axis{TemplateSize: t.ComputedSizeX, Length: bounds.Dx()}, 1, t.Justify)
+			y, _ := t.computePositionAndSpace(Axis{TemplateSize: t.ComputedSizeY, Length: bounds.Dy()}, 1, t.Align)
+			at = image.Pt(x, y).Add(state.Pos)
+			Composite(dst, im, at, state)
+		} else if t.Direction == "col" {
 			secondary.Length = bounds.Dx()
 			secondary.Position, _ = t.computePositionAndSpace(secondary, len(imList), t.Align)
-			t.Ctx.DrawImage(im, secondary.Position, primary.Position)
+			at = image.Pt(secondary.Position, primary.Position)
+			Composite(dst, im, at, state)
 			primary.Position += bounds.Dy()
 		} else {
 			secondary.Length = bounds.Dy()
 			secondary.Position, _ = t.computePositionAndSpace(secondary, len(imList), t.Align)
-			t.Ctx.DrawImage(im, primary.Position, secondary.Position)
+			at = image.Pt(primary.Position, secondary.Position)
+			Composite(dst, im, at, state)
 			primary.Position += bounds.Dx()
 		}
+		t.childBounds = append(t.childBounds, image.Rectangle{at, at.Add(bounds.Size())})
 
 		primary.Position += primary.Space // Increment only if it's space-between or space-around.
 	}
@@ -144,6 +195,12 @@ func (t *Template) Render() image.Image {
 	return t.Ctx.Image()
 }
 
+// ChildBounds returns where each child component was drawn during the last
+// Render, in the template's coordinates
+func (t *Template) ChildBounds() []image.Rectangle {
+	return t.childBounds
+}
+
 func (t *Template) Stop() {
 	for _, c := range t.Components {
 		c.Stop()
@@ -153,6 +210,7 @@ func (t *Template) Stop() {
 
 func (tmpl *Template) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	tmpl.XMLName = start.Name
+	tmpl.source.Tag = tagKey(start)
 
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
@@ -168,6 +226,16 @@ func (tmpl *Template) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 			tmpl.Direction = attr.Value
 		case "bg-color":
 			tmpl.BgColor = attr.Value
+		case "opacity":
+			tmpl.Opacity = attr.Value
+		case "blend":
+			tmpl.Blend = attr.Value
+		case "pos-x":
+			tmpl.PosX, _ = strconv.Atoi(attr.Value)
+		case "pos-y":
+			tmpl.PosY, _ = strconv.Atoi(attr.Value)
+		case "duration":
+			tmpl.Duration = attr.Value
 		}
 	}
 
@@ -176,19 +244,29 @@ func (tmpl *Template) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 		if err != nil {
 			return err
 		}
-		var i Component
 		switch tt := t.(type) {
 		case xml.StartElement:
-			i = RegisteredComponents[tt.Name.Local]()
-			// log.Printf("Invalid component type %s", tt.Name.Local)
-			if i != nil {
-				err = d.DecodeElement(i, &tt)
-				if err != nil {
+			markup, err := captureElement(d, tt)
+			if err != nil {
+				return err
+			}
+			if tt.Name.Local == "animate" {
+				a := &Animation{}
+				if err := xml.Unmarshal(markup, a); err != nil {
 					return err
 				}
-				tmpl.Components = append(tmpl.Components, i)
-				i = nil
+				tmpl.Animate = append(tmpl.Animate, a)
+				continue
+			}
+			if _, ok := RegisteredComponents[tt.Name.Local]; !ok {
+				log.Printf("Invalid component type %s", tt.Name.Local)
+				continue
+			}
+			i, err := decodeComponent(tt, markup)
+			if err != nil {
+				return err
 			}
+			tmpl.Components = append(tmpl.Components, i)
 		case xml.EndElement:
 			if tt == start.End() {
 				return nil
@@ -196,7 +274,6 @@ func (tmpl *Template) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 		}
 
 	}
-	return nil
 }
 
 func init() {
@@ -0,0 +1,20 @@
+package common
+
+import (
+	"log"
+	"time"
+)
+
+// ParseDuration parses a duration attribute such as "500ms" or "5s",
+// returning fallback when the value is empty or invalid
+func ParseDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid duration '%s', using %s", value, fallback)
+		return fallback
+	}
+	return d
+}
@@ -0,0 +1,168 @@
+// Package golden renders templates at fixed times with a fixed seed and
+// compares the frames to PNGs kept in the calling package's testdata, so
+// any change to how a component draws shows up as a failing test. Run the
+// tests with -update to rewrite the PNGs after an intended change
+package golden
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+var update = flag.Bool("update", false, "rewrite golden images instead of comparing against them")
+
+// Dir where golden images are kept, relative to the package under test
+const Dir = "testdata/golden"
+
+// Start the time every golden render's first frame is shown at
+var Start = time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// Seed the randomness every golden render draws from
+const Seed = 1
+
+// Fonts points the font directory at a temporary copy of the Go fonts,
+// available as Go-Regular, Go-Bold and Go-Mono, so text renders the same
+// on every machine
+func Fonts(t testing.TB) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"Go-Regular": goregular.TTF,
+		"Go-Bold":    gobold.TTF,
+		"Go-Mono":    gomono.TTF,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name+".ttf"), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := *util.Config
+	config.FontDir = dir
+	prev := util.Config
+	util.SetUtilConfig(&config)
+	t.Cleanup(func() { util.SetUtilConfig(prev) })
+}
+
+// Parse unmarshals and initializes a template from its markup
+func Parse(t testing.TB, markup string) *c.Template {
+	t.Helper()
+	tmpl := &c.Template{}
+	if err := xml.Unmarshal([]byte(markup), tmpl); err != nil {
+		t.Fatalf("Unable to unmarshal template: %v", err)
+	}
+	tmpl.Init()
+	return tmpl
+}
+
+// Frames renders an initialized template once for each time, given as time
+// since the first frame, and returns a copy of every frame
+func Frames(tmpl *c.Template, at ...time.Duration) []image.Image {
+	clock := c.NewClock(Start, Seed)
+	frames := make([]image.Image, 0, len(at))
+	for _, elapsed := range at {
+		im := tmpl.Render(clock.At(elapsed))
+		frame := image.NewRGBA(im.Bounds())
+		draw.Draw(frame, frame.Bounds(), im, im.Bounds().Min, draw.Src)
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+// Check compares each frame to <Dir>/<name>-<index>.png, or rewrites the
+// files when running with -update
+func Check(t testing.TB, name string, frames []image.Image) {
+	t.Helper()
+	for ix, frame := range frames {
+		path := filepath.Join(Dir, fmt.Sprintf("%s-%d.png", name, ix))
+		if *update {
+			if err := write(path, frame); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		want, err := read(path)
+		if err != nil {
+			t.Errorf("%v, run with -update to create it", err)
+			continue
+		}
+		if diff := compare(want, frame); diff != "" {
+			t.Errorf("%s: %s", path, diff)
+			// keep what was rendered next to the golden image for a look
+			actual := path[:len(path)-len(".png")] + ".actual.png"
+			if err := write(actual, frame); err == nil {
+				t.Logf("wrote %s", actual)
+			}
+		}
+	}
+}
+
+// Run parses the template, renders it at each time and checks the frames
+// against the golden images for name
+func Run(t testing.TB, name string, markup string, at ...time.Duration) {
+	t.Helper()
+	tmpl := Parse(t, markup)
+	defer tmpl.Stop()
+	Check(t, name, Frames(tmpl, at...))
+}
+
+// compare describes how two images differ, or returns "" if they don't.
+// Colors are compared unpremultiplied, the way PNGs store them
+func compare(want image.Image, got image.Image) string {
+	if want.Bounds().Size() != got.Bounds().Size() {
+		return fmt.Sprintf("size is %v, want %v", got.Bounds().Size(), want.Bounds().Size())
+	}
+	var diff int
+	var first image.Point
+	size := want.Bounds().Size()
+	for y := 0; y < size.Y; y++ {
+		for x := 0; x < size.X; x++ {
+			w := color.NRGBAModel.Convert(want.At(want.Bounds().Min.X+x, want.Bounds().Min.Y+y))
+			g := color.NRGBAModel.Convert(got.At(got.Bounds().Min.X+x, got.Bounds().Min.Y+y))
+			if w != g {
+				if diff == 0 {
+					first = image.Pt(x, y)
+				}
+				diff++
+			}
+		}
+	}
+	if diff == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d pixels differ, the first at %v", diff, first)
+}
+
+func read(path string) (image.Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(data))
+}
+
+func write(path string, im image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, im); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
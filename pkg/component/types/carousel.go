@@ -0,0 +1,200 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/fogleman/gg"
+)
+
+const carouselFrameRate = 30 // render rate in milliseconds
+
+// Carousel shows its templates one at a time, moving to the next after the
+// page's duration (or the carousel's interval) with a slide, fade or wipe
+type Carousel struct {
+	c.BaseComponent
+
+	XMLName       xml.Name      `xml:"carousel"`
+	Interval      string        `xml:"interval,attr"`       // default time on each page, e.g. "5s"
+	Transition    string        `xml:"transition,attr"`     // none, slide, fade or wipe
+	TransitionDur string        `xml:"transition-dur,attr"` // how long the transition takes
+	Direction     string        `xml:"dir,attr"`            // left, right, up or down, for slide and wipe
+	Easing        string        `xml:"easing,attr"`
+	Pages         []*c.Template `xml:"template"`
+
+	durations  []time.Duration
+	transition time.Duration
+	ease       c.EasingFunc
+	start      time.Time
+}
+
+func (cr *Carousel) Init() {
+	cr.Rr = carouselFrameRate
+	cr.BaseComponent.Init()
+
+	interval := c.ParseDuration(cr.Interval, 5*time.Second)
+	cr.durations = make([]time.Duration, len(cr.Pages))
+	for i, page := range cr.Pages {
+		page.SetParentSize(cr.ComputedSizeX, cr.ComputedSizeY)
+		page.Init()
+		cr.durations[i] = c.ParseDuration(page.Duration, interval)
+	}
+
+	// without a size the carousel is as big as its biggest page
+	if cr.SizeX == "" || cr.SizeY == "" {
+		for _, page := range cr.Pages {
+			if cr.SizeX == "" {
+				cr.ComputedSizeX = int(math.Max(float64(cr.ComputedSizeX), float64(page.ComputedSizeX)))
+			}
+			if cr.SizeY == "" {
+				cr.ComputedSizeY = int(math.Max(float64(cr.ComputedSizeY), float64(page.ComputedSizeY)))
+			}
+		}
+	}
+	if cr.ComputedSizeX <= 0 || cr.ComputedSizeY <= 0 {
+		log.Printf("Carousel has no size, give it a size-x and size-y")
+	}
+	if cr.Ctx == nil || cr.Ctx.Width() != cr.ComputedSizeX || cr.Ctx.Height() != cr.ComputedSizeY {
+		cr.Ctx = gg.NewContext(cr.ComputedSizeX, cr.ComputedSizeY)
+	}
+
+	if cr.Transition == "" {
+		cr.Transition = "slide"
+	}
+	if cr.Direction == "" {
+		cr.Direction = "left"
+	}
+	if cr.Easing == "" {
+		cr.Easing = "ease-in-out"
+	}
+	cr.ease = c.Easing(cr.Easing)
+	cr.transition = c.ParseDuration(cr.TransitionDur, 500*time.Millisecond)
+}
+
+// Reconcile stays on the page the carousel it replaces was showing,
+// carrying over what didn't change on each page
+func (cr *Carousel) Reconcile(prev c.Component) {
+	p, ok := prev.(*Carousel)
+	if !ok {
+		prev.Stop()
+		return
+	}
+	for i, page := range p.Pages {
+		if i < len(cr.Pages) {
+			cr.Pages[i].Reconcile(page)
+		} else {
+			page.Stop()
+		}
+	}
+	cr.start = p.start
+	p.BaseComponent.Stop()
+}
+
+// page returns the page showing after elapsed, the page before it, and how
+// far through the transition between them we are, from 0 to 1
+func (cr *Carousel) page(elapsed time.Duration) (int, int, float64) {
+	var cycle time.Duration
+	for _, d := range cr.durations {
+		cycle += d
+	}
+	if cycle <= 0 {
+		return 0, 0, 1
+	}
+
+	first := elapsed < cr.durations[0]
+	phase := elapsed % cycle
+	current := 0
+	for phase >= cr.durations[current] {
+		phase -= cr.durations[current]
+		current++
+	}
+	prev := (current + len(cr.Pages) - 1) % len(cr.Pages)
+
+	// the very first page just appears
+	if first || cr.Transition == "none" || cr.transition <= 0 || phase >= cr.transition {
+		return current, prev, 1
+	}
+	return current, prev, float64(phase) / float64(cr.transition)
+}
+
+func (cr *Carousel) Render(ctx *c.RenderContext) image.Image {
+	cr.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
+	cr.Ctx.Clear()
+	if len(cr.Pages) == 0 {
+		return cr.Ctx.Image()
+	}
+
+	if cr.start.IsZero() {
+		cr.start = ctx.Now
+	}
+	current, prev, progress := cr.page(ctx.Now.Sub(cr.start))
+	next := cr.Pages[current].Render(ctx)
+	if progress >= 1 || len(cr.Pages) == 1 {
+		cr.Ctx.DrawImage(next, 0, 0)
+		return cr.Ctx.Image()
+	}
+
+	before := cr.Pages[prev].Render(ctx)
+	progress = cr.ease(progress)
+	dst := cr.Ctx.Image().(*image.RGBA)
+	w, h := cr.ComputedSizeX, cr.ComputedSizeY
+
+	// offset of the incoming page at the start of the transition
+	var dx, dy int
+	switch cr.Direction {
+	case "right":
+		dx = -w
+	case "up":
+		dy = h
+	case "down":
+		dy = -h
+	default:
+		dx = w
+	}
+
+	switch cr.Transition {
+	case "fade":
+		cr.Ctx.DrawImage(before, 0, 0)
+		alpha := image.NewUniform(color.Alpha{uint8(progress * 255)})
+		draw.DrawMask(dst, dst.Bounds(), next, image.Point{}, alpha, image.Point{}, draw.Over)
+	case "wipe":
+		// the incoming page is uncovered from the leading edge
+		cr.Ctx.DrawImage(before, 0, 0)
+		shown := dst.Bounds()
+		switch {
+		case dx > 0:
+			shown.Min.X = w - int(progress*float64(w))
+		case dx < 0:
+			shown.Max.X = int(progress * float64(w))
+		case dy > 0:
+			shown.Min.Y = h - int(progress*float64(h))
+		default:
+			shown.Max.Y = int(progress * float64(h))
+		}
+		draw.Draw(dst, shown, next, shown.Min, draw.Src)
+	default:
+		ox := int(float64(dx) * (1 - progress))
+		oy := int(float64(dy) * (1 - progress))
+		cr.Ctx.DrawImage(before, ox-dx, oy-dy)
+		cr.Ctx.DrawImage(next, ox, oy)
+	}
+
+	return cr.Ctx.Image()
+}
+
+func (cr *Carousel) Stop() {
+	for _, page := range cr.Pages {
+		page.Stop()
+	}
+	cr.BaseComponent.Stop()
+}
+
+func init() {
+	c.RegisterComponent("carousel", func() c.Component { return &Carousel{} })
+}
@@ -24,7 +24,7 @@ func (cg *ColorGrid) Init() {
 	}
 }
 
-func (cg *ColorGrid) Render() image.Image {
+func (cg *ColorGrid) Render(ctx *c.RenderContext) image.Image {
 	cg.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	cg.Ctx.Clear()
 
@@ -31,7 +31,7 @@ func (cw *ColorWave) Init() {
 	}
 }
 
-func (cw *ColorWave) Render() image.Image {
+func (cw *ColorWave) Render(ctx *c.RenderContext) image.Image {
 	cw.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	cw.Ctx.Clear()
 
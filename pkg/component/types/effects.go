@@ -0,0 +1,115 @@
+package types
+
+import (
+	"image/color"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
+)
+
+// palettes named gradients for the procedural effects, each listing the
+// colors it passes through from 0 to 1
+var palettes = map[string][]string{
+	"fire":    {"#000000FF", "#800000FF", "#FF2000FF", "#FF8000FF", "#FFE040FF", "#FFFFFFFF"},
+	"rainbow": {"#FF0000FF", "#FFFF00FF", "#00FF00FF", "#00FFFFFF", "#0000FFFF", "#FF00FFFF", "#FF0000FF"},
+	"ocean":   {"#000010FF", "#002060FF", "#0060C0FF", "#00C0E0FF", "#C0FFFFFF"},
+	"sunset":  {"#100020FF", "#602080FF", "#E04060FF", "#FF8040FF", "#FFE080FF"},
+	"forest":  {"#000800FF", "#104010FF", "#208020FF", "#80C040FF", "#E0FF80FF"},
+	"mono":    {"#000000FF", "#FFFFFFFF"},
+	"meter":   {"#00C000FF", "#00FF00FF", "#FFFF00FF", "#FF0000FF"},
+}
+
+// palette a 256 step lookup table built from a gradient
+type palette [256]color.RGBA
+
+// paletteColors the colors of a palette name or comma separated list of
+// colors
+func paletteColors(value string) ([]color.NRGBA, error) {
+	if stops, ok := palettes[value]; ok {
+		value = strings.Join(stops, ",")
+	}
+	return colors.ParseList(value)
+}
+
+// parsePalette builds a palette from a palette name or a comma separated
+// list of colors, using the fallback palette when the value is empty or
+// invalid
+func parsePalette(value string, fallback string) *palette {
+	stops, err := paletteColors(value)
+	if err != nil {
+		if value != "" {
+			log.Printf("Invalid palette '%s', using %s: %v", value, fallback, err)
+		}
+		stops, _ = paletteColors(fallback)
+	}
+	p := &palette{}
+	for i := range p {
+		if len(stops) == 1 {
+			p[i] = color.RGBAModel.Convert(stops[0]).(color.RGBA)
+			continue
+		}
+		pos := float64(i) / 255 * float64(len(stops)-1)
+		j := int(math.Min(pos, float64(len(stops)-2)))
+		t := pos - float64(j)
+		lerp := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t)) }
+		from, to := stops[j], stops[j+1]
+		p[i] = color.RGBAModel.Convert(color.NRGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), lerp(from.A, to.A)}).(color.RGBA)
+	}
+	return p
+}
+
+// at returns the color v of the way along the palette, v from 0 to 1
+func (p *palette) at(v float64) color.RGBA {
+	return p[int(math.Max(0, math.Min(255, v*255)))]
+}
+
+// effect what the procedural effects share, a palette, a speed and a clock
+// that steps their simulations at a fixed rate however often they render
+type effect struct {
+	Palette string  `xml:"palette,attr"` // palette name, or comma separated colors
+	Speed   float64 `xml:"speed,attr"`   // 1 (default) is normal, 2 twice as fast
+
+	palette *palette
+	start   time.Time
+	last    time.Time
+	owed    float64
+}
+
+// initEffect fills in defaults, with fallback the palette used when none
+// is given
+func (e *effect) initEffect(fallback string) {
+	if e.Speed <= 0 {
+		e.Speed = 1
+	}
+	e.palette = parsePalette(e.Palette, fallback)
+}
+
+// elapsed returns how far the effect's clock has run at now, in seconds
+// scaled by speed. It starts on the first frame
+func (e *effect) elapsed(now time.Time) float64 {
+	if e.start.IsZero() {
+		e.start = now
+	}
+	return now.Sub(e.start).Seconds() * e.Speed
+}
+
+// steps returns how many simulation steps are due at now, for a
+// simulation that normally runs rate steps a second. The first frame
+// always gets one step and a long stall doesn't pile them up
+func (e *effect) steps(now time.Time, rate float64) int {
+	if e.last.IsZero() {
+		e.last = now
+		return 1
+	}
+	e.owed += now.Sub(e.last).Seconds() * rate * e.Speed
+	e.last = now
+	n := int(e.owed)
+	e.owed -= float64(n)
+	if limit := int(rate * e.Speed / 2); n > limit && limit > 0 {
+		n = limit
+	}
+	return n
+}
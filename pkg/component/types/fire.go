@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+const fireStepRate = 30 // simulation steps per second
+
+// Fire the classic demo fire, heat rising from the bottom row and cooling
+// as it climbs, colored through the palette
+type Fire struct {
+	c.BaseComponent
+	effect
+
+	XMLName   xml.Name `xml:"fire"`
+	Intensity float64  `xml:"intensity,attr"` // heat fed in at the bottom, 0 to 1, defaults to 1
+	Wind      float64  `xml:"wind,attr"`      // how much the flames lean, -1 (left) to 1 (right)
+	heat      []float64
+}
+
+func (f *Fire) Init() {
+	f.Rr = effectFrameRate
+	f.BaseComponent.Init()
+	f.initEffect("fire")
+	if f.Intensity <= 0 {
+		f.Intensity = 1
+	}
+	f.heat = make([]float64, f.ComputedSizeX*f.ComputedSizeY)
+}
+
+// step moves the heat up a row, spreading and cooling it on the way
+func (f *Fire) step(ctx *c.RenderContext) {
+	w, h := f.ComputedSizeX, f.ComputedSizeY
+	for x := 0; x < w; x++ {
+		f.heat[(h-1)*w+x] = f.Intensity * (0.7 + 0.3*ctx.Rand.Float64())
+	}
+	// taller displays need slower cooling for the flames to reach as high
+	cooling := 3.0 / float64(h)
+	for y := 0; y < h-1; y++ {
+		for x := 0; x < w; x++ {
+			drift := ctx.Rand.Intn(3) - 1
+			if ctx.Rand.Float64() < f.Wind {
+				drift = 1
+			} else if ctx.Rand.Float64() < -f.Wind {
+				drift = -1
+			}
+			from := x - drift
+			if from < 0 || from >= w {
+				from = x
+			}
+			below := f.heat[(y+1)*w+from]
+			f.heat[y*w+x] = below - ctx.Rand.Float64()*cooling
+			if f.heat[y*w+x] < 0 {
+				f.heat[y*w+x] = 0
+			}
+		}
+	}
+}
+
+func (f *Fire) Render(ctx *c.RenderContext) image.Image {
+	for n := f.steps(ctx.Now, fireStepRate); n > 0; n-- {
+		f.step(ctx)
+	}
+
+	dst := f.Ctx.Image().(*image.RGBA)
+	for y := 0; y < f.ComputedSizeY; y++ {
+		for x := 0; x < f.ComputedSizeX; x++ {
+			dst.SetRGBA(x, y, f.palette.at(f.heat[y*f.ComputedSizeX+x]))
+		}
+	}
+	return dst
+}
+
+func init() {
+	c.RegisterComponent("fire", func() c.Component { return &Fire{} })
+}
@@ -0,0 +1,132 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+const fireworksStepRate = 30 // simulation steps per second
+
+// Fireworks launches rockets from the bottom that burst into sparks,
+// each burst a color picked from the palette
+type Fireworks struct {
+	c.BaseComponent
+	effect
+
+	XMLName  xml.Name `xml:"fireworks"`
+	Rate     float64  `xml:"rate,attr"`   // launches per second, defaults to 1
+	Sparks   int      `xml:"sparks,attr"` // sparks in each burst, defaults to 40
+	rockets  []spark
+	sparks   []spark
+	launches float64 // rockets due but not launched yet
+}
+
+type spark struct {
+	x, y, vx, vy float64 // pixels and pixels per step
+	life, age    int     // steps
+	color        color.RGBA
+}
+
+func (f *Fireworks) Init() {
+	f.Rr = effectFrameRate
+	f.BaseComponent.Init()
+	f.initEffect("rainbow")
+	if f.Rate <= 0 {
+		f.Rate = 1
+	}
+	if f.Sparks <= 0 {
+		f.Sparks = 40
+	}
+}
+
+// step launches, moves and bursts rockets, and moves and fades sparks
+func (f *Fireworks) step(ctx *c.RenderContext) {
+	w, h := float64(f.ComputedSizeX), float64(f.ComputedSizeY)
+	// rockets reach the top of their climb in about 20 steps, sparks drift
+	// down slower so bursts hang in the air
+	gravity := h / 300
+
+	f.launches += f.Rate / fireworksStepRate
+	if f.rockets == nil {
+		// launch the first straight away
+		f.launches = 1
+	}
+	for ; f.launches >= 1; f.launches-- {
+		// fast enough to climb most of the way up before slowing to a stop
+		vy := -math.Sqrt(2 * gravity * h * (0.5 + 0.4*ctx.Rand.Float64()))
+		f.rockets = append(f.rockets, spark{
+			x:     w * (0.2 + 0.6*ctx.Rand.Float64()),
+			y:     h - 1,
+			vx:    (ctx.Rand.Float64() - 0.5) * w / 300,
+			vy:    vy,
+			color: color.RGBA{255, 255, 255, 255},
+		})
+	}
+
+	rockets := f.rockets[:0]
+	for _, r := range f.rockets {
+		r.x += r.vx
+		r.y += r.vy
+		r.vy += gravity
+		if r.vy < 0 {
+			rockets = append(rockets, r)
+			continue
+		}
+		// burst at the top of the climb
+		col := f.palette.at(ctx.Rand.Float64())
+		power := h / 80
+		for i := 0; i < f.Sparks; i++ {
+			angle := ctx.Rand.Float64() * 2 * math.Pi
+			speed := power * (0.3 + 0.7*ctx.Rand.Float64())
+			f.sparks = append(f.sparks, spark{
+				x: r.x, y: r.y,
+				vx: math.Cos(angle) * speed, vy: math.Sin(angle) * speed,
+				life:  fireworksStepRate + ctx.Rand.Intn(fireworksStepRate/2),
+				color: col,
+			})
+		}
+	}
+	f.rockets = rockets
+
+	sparks := f.sparks[:0]
+	for _, s := range f.sparks {
+		s.age++
+		if s.age >= s.life {
+			continue
+		}
+		s.x += s.vx
+		s.y += s.vy
+		s.vx *= 0.95
+		s.vy = s.vy*0.95 + gravity
+		sparks = append(sparks, s)
+	}
+	f.sparks = sparks
+}
+
+func (f *Fireworks) Render(ctx *c.RenderContext) image.Image {
+	for n := f.steps(ctx.Now, fireworksStepRate); n > 0; n-- {
+		f.step(ctx)
+	}
+
+	f.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
+	f.Ctx.Clear()
+	dst := f.Ctx.Image().(*image.RGBA)
+	for _, r := range f.rockets {
+		dst.SetRGBA(int(r.x), int(r.y), r.color)
+	}
+	for _, s := range f.sparks {
+		col := color.NRGBA{s.color.R, s.color.G, s.color.B, uint8(255 * (1 - float64(s.age)/float64(s.life)))}
+		at := image.Rect(0, 0, 1, 1).Add(image.Pt(int(math.Floor(s.x)), int(math.Floor(s.y))))
+		draw.Draw(dst, at, image.NewUniform(col), image.Point{}, draw.Over)
+	}
+	return dst
+}
+
+func init() {
+	c.RegisterComponent("fireworks", func() c.Component { return &Fireworks{} })
+}
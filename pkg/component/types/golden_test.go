@@ -0,0 +1,347 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/component/golden"
+)
+
+const ms = time.Millisecond
+
+func TestGolden(t *testing.T) {
+	golden.Fonts(t)
+
+	tests := []struct {
+		name   string
+		markup string
+		at     []time.Duration
+	}{
+		{
+			name: "text",
+			markup: `<template size-x="64" size-y="32" dir="col" justify="center" align="center">
+				<text font="Go" style="Regular" size="10" color="#FFFFFFFF">Hello <span color="#FF0000FF">red</span></text>
+				<text font="Go" style="Bold" size="10" color="#66CCFFFF" outline-color="#000000FF" shadow-color="#333333FF">Bold</text>
+			</template>`,
+			at: []time.Duration{0},
+		},
+		{
+			name: "text-wrap",
+			markup: `<template size-x="48" size-y="32">
+				<text font="Go" style="Regular" size="9" color="#FFFFFFFF" size-x="48" size-y="32" wrap="true" max-lines="3" text-align="center" overflow="ellipsis">The quick brown fox jumps over the lazy dog</text>
+			</template>`,
+			at: []time.Duration{0},
+		},
+		{
+			name: "text-marquee",
+			markup: `<template size-x="32" size-y="12">
+				<text font="Go" style="Regular" size="10" color="#FFFF00FF" size-x="32" overflow="scroll" scroll-mode="loop" scroll-speed="20" scroll-pause="0s">Scrolling along</text>
+			</template>`,
+			at: []time.Duration{0, 500 * ms, 1500 * ms},
+		},
+		{
+			name: "text-effects",
+			markup: `<template size-x="64" size-y="24" dir="col">
+				<text font="Go" style="Regular" size="10" color="#FFFFFFFF" effect="typewriter" effect-speed="10">Typing text</text>
+				<text font="Go" style="Regular" size="10" color="#FFFFFFFF" effect="glitch" effect-amount="1">Glitch</text>
+			</template>`,
+			at: []time.Duration{0, 300 * ms, 800 * ms},
+		},
+		{
+			name: "rainbow-text",
+			markup: `<template size-x="64" size-y="16" justify="center" align="center">
+				<rainbow-text font="Go" style="Regular" size="12" color="#FFFFFFFF">Rainbow</rainbow-text>
+			</template>`,
+			at: []time.Duration{0, 500 * ms},
+		},
+		{
+			name: "scroller",
+			markup: `<template size-x="32" size-y="16">
+				<scroller size-x="32" size-y="16" speed-x="20" gap="4">
+					<template size-x="40" size-y="16" bg-color="#202020FF">
+						<text font="Go" style="Regular" size="10" color="#00FF00FF">Ticker</text>
+					</template>
+				</scroller>
+			</template>`,
+			at: []time.Duration{0, 400 * ms, 1200 * ms},
+		},
+		{
+			name: "carousel",
+			markup: `<template size-x="32" size-y="16">
+				<carousel size-x="32" size-y="16" interval="1s" transition="slide" transition-dur="400ms" easing="linear">
+					<template size-x="32" size-y="16" bg-color="#FF0000FF"></template>
+					<template size-x="32" size-y="16" bg-color="#0000FFFF"></template>
+				</carousel>
+			</template>`,
+			at: []time.Duration{0, 1200 * ms, 1600 * ms},
+		},
+		{
+			name: "animate",
+			markup: `<template size-x="32" size-y="16" dir="stack">
+				<template size-x="8" size-y="8" bg-color="#FFFFFFFF">
+					<animate attr="pos-x" from="-12" to="12" dur="1s" easing="linear"/>
+				</template>
+			</template>`,
+			at: []time.Duration{0, 500 * ms, 1000 * ms},
+		},
+		{
+			// animated children of nested templates keep moving
+			name: "nested",
+			markup: `<template size-x="64" size-y="40" dir="col">
+				<template size-x="64" size-y="12">
+					<text size-x="64" overflow="scroll" font="Go" style="Regular" size="8" color="#FFFFFFFF">a headline far too long to fit</text>
+				</template>
+				<template size-x="64" size-y="4" dir="stack">
+					<template size-x="4" size-y="4" bg-color="#FF0000FF">
+						<animate attr="pos-x" from="-24" to="24" dur="2s" easing="linear"/>
+					</template>
+				</template>
+				<template size-x="64" size-y="24">
+					<image size-x="24" size-y="24" src="testdata/wave.gif" scaling="nearest" loop="true"></image>
+				</template>
+			</template>`,
+			at: []time.Duration{0, 500 * ms, 1500 * ms},
+		},
+		{
+			name: "image",
+			markup: `<template size-x="24" size-y="24">
+				<image size-x="24" size-y="24" src="testdata/wave.gif" scaling="nearest" loop="true"></image>
+			</template>`,
+			at: []time.Duration{0, 100 * ms, 200 * ms},
+		},
+		{
+			// without loop or play an animation stays on its first frame
+			name: "image-default",
+			markup: `<template size-x="24" size-y="24">
+				<image size-x="24" size-y="24" src="testdata/wave.gif" scaling="nearest"></image>
+			</template>`,
+			at: []time.Duration{0, 200 * ms},
+		},
+		{
+			name: "sprite",
+			markup: `<template size-x="16" size-y="16">
+				<sprite size-x="16" size-y="16" src="testdata/sprite.png" frame-w="8" frame-h="8" fps="10" play="ping-pong"></sprite>
+			</template>`,
+			at: []time.Duration{0, 100 * ms, 300 * ms, 400 * ms},
+		},
+		{
+			name: "qr",
+			markup: `<template size-x="32" size-y="32">
+				<qr size-x="32" size-y="32" data="HELLO" quiet-zone="1"></qr>
+			</template>`,
+			at: []time.Duration{0},
+		},
+		{
+			name: "color-grid",
+			markup: `<template size-x="32" size-y="16">
+				<color-grid size-x="32" size-y="16" gridSize="4"></color-grid>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "colorwave",
+			markup: `<template size-x="32" size-y="16">
+				<colorwave size-x="32" size-y="16" frequency="0.2" amplitude="1" speed="0.1"></colorwave>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "pulsing-circles",
+			markup: `<template size-x="32" size-y="16">
+				<pulsing-circles size-x="32" size-y="16" numCircles="3" maxRadius="8"></pulsing-circles>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "paddleball",
+			markup: `<template size-x="32" size-y="16">
+				<paddleball size-x="32" size-y="16" ballRadius="1" paddleHeight="6" paddleWidth="1" color="#FFFFFFFF"></paddleball>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "gravity-particles",
+			markup: `<template size-x="32" size-y="16">
+				<gravity-particles size-x="32" size-y="16"></gravity-particles>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "particles",
+			markup: `<template size-x="32" size-y="16">
+				<particles size-x="32" size-y="16" bounds="bounce">
+					<emitter x="16" y="15" rate="60" burst="5" angle="-90" spread="25" speed="30" speed-var="8" life="1s" colors="#FFFF80FF,#FF8000FF,#FF000000"/>
+					<emitter x="0" y="0" width="32" rate="10" angle="90" spread="0" speed="10" size="1" colors="#4080FFFF"/>
+					<force type="gravity" y="30"/>
+					<force type="wind" x="-5" gust="10"/>
+					<force type="attractor" x="28" y="4" strength="200" radius="10"/>
+				</particles>
+			</template>`,
+			at: []time.Duration{0, 100 * ms, 200 * ms, 300 * ms, 400 * ms, 500 * ms},
+		},
+		{
+			name: "particles-dissolve",
+			markup: `<template size-x="32" size-y="16">
+				<particles size-x="32" size-y="16">
+					<template size-x="32" size-y="16" justify="center" align="center">
+						<text font="Go" style="Regular" size="10" color="#FFFFFFFF">Hi!</text>
+					</template>
+					<emitter source="template" delay="100ms" rate="500" angle="-90" spread="60" speed="15" life="500ms" colors="#FFC040FF,#FF400000"/>
+					<force type="drag" strength="0.5"/>
+				</particles>
+			</template>`,
+			at: []time.Duration{0, 100 * ms, 200 * ms, 300 * ms, 400 * ms},
+		},
+		{
+			name: "matrix-rain",
+			markup: `<template size-x="32" size-y="16">
+				<matrix-rain size-x="32" size-y="16"></matrix-rain>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "spiral",
+			markup: `<template size-x="32" size-y="32">
+				<spiral size-x="32" size-y="32">
+					<template size-x="6" size-y="6" bg-color="#FF00FFFF"></template>
+					<template size-x="6" size-y="6" bg-color="#00FFFFFF"></template>
+				</spiral>
+			</template>`,
+			at: []time.Duration{0, 100 * ms},
+		},
+		{
+			name: "fire",
+			markup: `<template size-x="32" size-y="16">
+				<fire size-x="32" size-y="16" wind="0.5"></fire>
+			</template>`,
+			at: []time.Duration{0, 200 * ms, 500 * ms},
+		},
+		{
+			name: "plasma",
+			markup: `<template size-x="32" size-y="16">
+				<plasma size-x="32" size-y="16" scale="4" palette="#000080FF,#00FFFFFF,#FFFFFFFF" speed="2"></plasma>
+			</template>`,
+			at: []time.Duration{0, 500 * ms},
+		},
+		{
+			name: "starfield",
+			markup: `<template size-x="32" size-y="16">
+				<starfield size-x="32" size-y="16"></starfield>
+			</template>`,
+			at: []time.Duration{0, 200 * ms, 400 * ms},
+		},
+		{
+			name: "fireworks",
+			markup: `<template size-x="32" size-y="16">
+				<fireworks size-x="32" size-y="16" rate="2" sparks="20"></fireworks>
+			</template>`,
+			at: []time.Duration{0, 500 * ms, 1000 * ms, 1500 * ms},
+		},
+		{
+			name: "life",
+			markup: `<template size-x="32" size-y="16">
+				<life size-x="32" size-y="16" palette="forest"></life>
+			</template>`,
+			at: []time.Duration{0, 300 * ms, 600 * ms},
+		},
+		{
+			name: "life-text",
+			markup: `<template size-x="32" size-y="16">
+				<life size-x="32" size-y="16" hold="200ms" wrap="false">
+					<template size-x="32" size-y="16" justify="center" align="center">
+						<text font="Go" style="Regular" size="10" color="#FFFFFFFF">Hi!</text>
+					</template>
+				</life>
+			</template>`,
+			at: []time.Duration{0, 300 * ms, 600 * ms},
+		},
+		{
+			name: "metaballs",
+			markup: `<template size-x="32" size-y="16">
+				<metaballs size-x="32" size-y="16" count="3" palette="sunset"></metaballs>
+			</template>`,
+			at: []time.Duration{0, 500 * ms},
+		},
+		{
+			name: "visualizer-bars",
+			markup: `<template size-x="32" size-y="16">
+				<visualizer size-x="32" size-y="16" src="testdata/chirp.wav"></visualizer>
+			</template>`,
+			at: []time.Duration{100 * ms, 400 * ms, 800 * ms},
+		},
+		{
+			name: "visualizer-wave",
+			markup: `<template size-x="32" size-y="16">
+				<visualizer size-x="32" size-y="16" mode="wave" palette="ocean" gain="1.5" src="testdata/chirp.wav"></visualizer>
+			</template>`,
+			at: []time.Duration{100 * ms, 800 * ms},
+		},
+		{
+			name: "visualizer-vu",
+			markup: `<template size-x="32" size-y="16">
+				<visualizer size-x="32" size-y="16" mode="vu" floor="-30" src="testdata/chirp.wav"></visualizer>
+			</template>`,
+			at: []time.Duration{0, 500 * ms},
+		},
+		{
+			name: "table",
+			markup: `<template size-x="64" size-y="32">
+				<table size-x="64" cols="auto,12,12" align="left,right,right" gap-x="2" stripe="#FFFFFF30">
+					<tr bg-color="#202080FF">
+						<td><text font="Go" style="Bold" size="7" color="#FFFFFFFF">Team</text></td>
+						<td><text font="Go" style="Bold" size="7" color="#FFFFFFFF">W</text></td>
+						<td><text font="Go" style="Bold" size="7" color="#FFFFFFFF">L</text></td>
+					</tr>
+					<tr>
+						<td><text font="Go" style="Regular" size="7" color="#66CCFFFF">Eagles</text></td>
+						<td><text font="Go" style="Regular" size="7" color="#FFFFFFFF">10</text></td>
+						<td><text font="Go" style="Regular" size="7" color="#FFFFFFFF">2</text></td>
+					</tr>
+					<tr>
+						<td><text font="Go" style="Regular" size="7" color="#66CCFFFF">Cowboys</text></td>
+						<td><text font="Go" style="Regular" size="7" color="#FFFFFFFF">9</text></td>
+						<td><text font="Go" style="Regular" size="7" color="#FFFFFFFF">3</text></td>
+					</tr>
+					<tr height="8">
+						<td span="3" align="center" valign="bottom"><text font="Go" style="Regular" size="6" color="#FF4542FF">Week 12</text></td>
+					</tr>
+				</table>
+			</template>`,
+			at: []time.Duration{0},
+		},
+		{
+			name: "list",
+			markup: `<template size-x="64" size-y="32">
+				<list size-x="64" size-y="32" interval="1s" transition-dur="500ms" easing="linear">
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 1</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">3.1</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 2</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">6.2</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 3</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">9.3</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 4</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">12.4</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 5</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">15.5</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 6</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">18.6</text></li>
+				</list>
+			</template>`,
+			at: []time.Duration{0, 1250 * ms, 1600 * ms},
+		},
+		{
+			name: "list-scroll",
+			markup: `<template size-x="64" size-y="32">
+				<list size-x="64" size-y="32" mode="scroll" interval="1s" transition-dur="500ms" easing="linear">
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 1</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">3.1</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 2</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">6.2</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 3</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">9.3</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 4</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">12.4</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 5</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">15.5</text></li>
+					<li justify="space-between"><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Player 6</text><text font="Go" style="Regular" size="7" color="#66CCFFFF">18.6</text></li>
+				</list>
+			</template>`,
+			at: []time.Duration{0, 1250 * ms, 2600 * ms},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden.Run(t, tt.name, tt.markup, tt.at...)
+		})
+	}
+}
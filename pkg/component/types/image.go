@@ -3,13 +3,14 @@ package types
 import (
 	"bytes"
 	"encoding/xml"
+	"fmt"
 	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
 	"github.com/6ixisgood/matrix-ticker/pkg/util"
 	"github.com/srwiley/oksvg"
 	"github.com/srwiley/rasterx"
 	"image"
-	"image/gif"
 	"log"
+	"math"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,18 +19,49 @@ import (
 type Image struct {
 	c.BaseComponent
 
-	XMLName      xml.Name `xml:"image"`
-	Src          string   `xml:"src,attr"`
-	Loop         bool     `xml:"loop,attr"`
-	frames       []image.Image
-	currentFrame int
+	XMLName    xml.Name  `xml:"image"`
+	Src        string    `xml:"src,attr"`
+	Loop       bool      `xml:"loop,attr"`    // loop forever, whatever the file's loop count
+	Play       string    `xml:"play,attr"`    // loop, once, ping-pong or static, static unless loop is set
+	Fit        string    `xml:"fit,attr"`     // stretch (default), contain, cover or none
+	Focus      string    `xml:"focus,attr"`   // point to keep in view when cropping, e.g. "0.5,0"
+	Crop       string    `xml:"crop,attr"`    // region of the source to use, "x,y,w,h"
+	Scaling    string    `xml:"scaling,attr"` // smooth (default) or nearest, for pixel art
+	Grayscale  bool      `xml:"grayscale,attr"`
+	Tint       util.RGBA `xml:"tint,attr"`
+	Brightness float64   `xml:"brightness,attr"` // -1 to 1
+	Contrast   float64   `xml:"contrast,attr"`   // 1 leaves contrast unchanged
+	Dither     string    `xml:"dither,attr"`     // palette name, e.g. bw, gray4, rgb8, websafe
+	frames     []image.Image
+	delays     []time.Duration
+	loops      int // how many times the file says to play, 0 for forever
+	playback   playback
+}
+
+// fit builds the scaling options from the component's attributes
+func (i *Image) fit() util.ImageFit {
+	fit := util.ImageFit{Fit: i.Fit, FocusX: 0.5, FocusY: 0.5, Nearest: i.Scaling == "nearest"}
+	if i.Focus != "" {
+		if _, err := fmt.Sscanf(i.Focus, "%g,%g", &fit.FocusX, &fit.FocusY); err != nil {
+			log.Printf("Invalid image focus '%s'", i.Focus)
+		}
+	}
+	if i.Crop != "" {
+		var x, y, w, h int
+		if _, err := fmt.Sscanf(i.Crop, "%d,%d,%d,%d", &x, &y, &w, &h); err != nil {
+			log.Printf("Invalid image crop '%s'", i.Crop)
+		} else {
+			fit.Crop = image.Rect(x, y, x+w, y+h)
+		}
+	}
+	return fit
 }
 
 func (i *Image) Init() {
 	i.Rr = -1
 	i.BaseComponent.Init()
 
-	data, filePath, err := util.FetchImage(i.Src, i.ComputedSizeX, i.ComputedSizeY)
+	data, filePath, err := util.FetchFile(i.Src)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -37,22 +69,24 @@ func (i *Image) Init() {
 	extension := strings.ToLower(filepath.Ext(filePath))
 
 	// Decode based on extension
-	if extension == ".gif" {
-		gifData, err := gif.DecodeAll(bytes.NewReader(data))
+	if extension == ".gif" || extension == ".webp" || extension == ".png" || extension == ".apng" {
+		// these may be animated, so every frame is decoded and scaled
+		anim, err := util.DecodeAnimation(data)
 		if err != nil {
 			log.Fatal(err)
 		}
-
-		for _, frame := range gifData.Image {
-			i.frames = append(i.frames, frame)
-		}
-
-		// If the GIF should loop, reset the ticker accordingly
-		if i.Loop {
-			i.Ticker = time.NewTicker(time.Duration(gifData.Delay[0]) * 10 * time.Millisecond)
+		fit := i.fit()
+		for _, frame := range anim.Frames {
+			i.frames = append(i.frames, util.FitImage(frame, i.ComputedSizeX, i.ComputedSizeY, fit))
 		}
-	} else if extension == ".png" || extension == ".jpg" || extension == ".jpeg" {
+		i.delays = anim.Delays
+		i.loops = anim.LoopCount
+	} else if extension == ".jpg" || extension == ".jpeg" {
 		// do we have it saved in this size yet?
+		data, _, err := util.FetchImageFit(i.Src, i.ComputedSizeX, i.ComputedSizeY, i.fit())
+		if err != nil {
+			log.Fatal(err)
+		}
 		img, _, err := image.Decode(bytes.NewReader(data))
 		if err != nil {
 			log.Fatal(err)
@@ -69,6 +103,20 @@ func (i *Image) Init() {
 		width := int(i.ComputedSizeX)
 		height := int(i.ComputedSizeY)
 
+		// keep the aspect ratio unless stretching
+		fit := i.fit()
+		vw, vh := svg.ViewBox.W, svg.ViewBox.H
+		if fit.Fit != "" && fit.Fit != "stretch" && vw > 0 && vh > 0 {
+			scale := 1.0
+			switch fit.Fit {
+			case "contain":
+				scale = math.Min(float64(width)/vw, float64(height)/vh)
+			case "cover":
+				scale = math.Max(float64(width)/vw, float64(height)/vh)
+			}
+			width, height = int(math.Round(vw*scale)), int(math.Round(vh*scale))
+		}
+
 		// Create an RGBA canvas to render the SVG
 		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
 
@@ -82,17 +130,39 @@ func (i *Image) Init() {
 		// Draw the SVG onto the RGBA canvas
 		svg.Draw(dasher, 1)
 
-		i.frames = append(i.frames, rgba)
+		// place it within the box
+		fit.Fit, fit.Crop = "none", image.Rectangle{}
+		i.frames = append(i.frames, util.FitImage(rgba, i.ComputedSizeX, i.ComputedSizeY, fit))
 	} else {
 		log.Fatal("Unsupported file extension")
 	}
+
+	filter := util.ImageFilter{
+		Grayscale:  i.Grayscale,
+		Tint:       i.Tint.RGBA,
+		Brightness: i.Brightness,
+		Contrast:   i.Contrast,
+		Dither:     i.Dither,
+	}
+	for ix, frame := range i.frames {
+		i.frames[ix] = filter.Apply(frame)
+	}
+
+	// animations only play when asked to, as they always have
+	play, loops := i.Play, i.loops
+	if i.Loop {
+		loops = 0
+	}
+	if play == "" && !i.Loop {
+		play = "static"
+	}
+	if rate := i.playback.init(i.delays, play, loops); rate > 0 {
+		i.SetRate(rate)
+	}
 }
 
-func (i *Image) Render() image.Image {
-	// Return the current frame and update the frame index
-	img := i.frames[i.currentFrame]
-	i.currentFrame = (i.currentFrame + 1) % len(i.frames)
-	return img
+func (i *Image) Render(ctx *c.RenderContext) image.Image {
+	return i.frames[i.playback.frame(ctx.Now)]
 }
 
 func init() {
@@ -0,0 +1,177 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"math"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+const lifeStepRate = 10 // generations per second
+
+// Life Conway's Game of Life, one cell per pixel. Cells are colored by
+// age along the palette, newborns at its end. The board is seeded at random,
+// or from the lit pixels of a child template so e.g. text comes to life
+type Life struct {
+	c.BaseComponent
+	effect
+
+	XMLName xml.Name    `xml:"life"`
+	Density float64     `xml:"density,attr"` // share of cells alive in a random seed, defaults to 0.3
+	Wrap    *bool       `xml:"wrap,attr"`    // whether the edges join up, defaults to true
+	Reseed  *bool       `xml:"reseed,attr"`  // start over once the board dies out or settles, defaults to true
+	Hold    string      `xml:"hold,attr"`    // how long to show the seed before it starts living
+	Slot    *c.Template `xml:"template"`     // seeds the board from its lit pixels
+	age     []int       // generations each cell has lived, 0 for dead
+	next    []int
+	history [2]uint64 // hashes of the last two boards, to spot still lifes and blinkers
+	seeded  bool
+	held    int // generations left to hold the seed for
+}
+
+func (l *Life) Init() {
+	l.Rr = effectFrameRate
+	l.BaseComponent.Init()
+	l.initEffect("mono")
+	if l.Density <= 0 {
+		l.Density = 0.3
+	}
+	if l.Wrap == nil {
+		wrap := true
+		l.Wrap = &wrap
+	}
+	if l.Reseed == nil {
+		reseed := true
+		l.Reseed = &reseed
+	}
+	l.age = make([]int, l.ComputedSizeX*l.ComputedSizeY)
+	l.next = make([]int, len(l.age))
+	if l.Slot != nil {
+		l.Slot.SetParentSize(l.ComputedSizeX, l.ComputedSizeY)
+		l.Slot.Init()
+	}
+}
+
+// seed fills the board from the child template the first time, and at
+// random otherwise
+func (l *Life) seed(ctx *c.RenderContext) {
+	for i := range l.age {
+		l.age[i] = 0
+	}
+	l.history = [2]uint64{}
+	l.held = int(c.ParseDuration(l.Hold, 0).Seconds() * lifeStepRate)
+
+	if l.Slot != nil && !l.seeded {
+		im := l.Slot.Render(ctx)
+		bg := color.NRGBAModel.Convert(c.ParseBgColor(l.Slot.BgColor)).(color.NRGBA)
+		b := im.Bounds()
+		for y := 0; y < l.ComputedSizeY && y < b.Dy(); y++ {
+			for x := 0; x < l.ComputedSizeX && x < b.Dx(); x++ {
+				px := color.NRGBAModel.Convert(im.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+				if px.A > 0 && px != bg {
+					l.age[y*l.ComputedSizeX+x] = 1
+				}
+			}
+		}
+	} else {
+		for i := range l.age {
+			if ctx.Rand.Float64() < l.Density {
+				l.age[i] = 1
+			}
+		}
+	}
+	l.seeded = true
+}
+
+// alive reports whether the cell at x, y is alive, wrapping round the
+// edges if enabled
+func (l *Life) alive(x int, y int) bool {
+	w, h := l.ComputedSizeX, l.ComputedSizeY
+	if *l.Wrap {
+		x, y = (x+w)%w, (y+h)%h
+	} else if x < 0 || y < 0 || x >= w || y >= h {
+		return false
+	}
+	return l.age[y*w+x] > 0
+}
+
+// step moves the board on a generation, returning false once it has died
+// out or stopped changing
+func (l *Life) step() bool {
+	w, h := l.ComputedSizeX, l.ComputedSizeY
+	var hash uint64 = 14695981039346656037
+	population := 0
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			n := 0
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					if (dx != 0 || dy != 0) && l.alive(x+dx, y+dy) {
+						n++
+					}
+				}
+			}
+			i := y*w + x
+			switch {
+			case l.age[i] > 0 && (n == 2 || n == 3):
+				l.next[i] = l.age[i] + 1
+			case l.age[i] == 0 && n == 3:
+				l.next[i] = 1
+			default:
+				l.next[i] = 0
+			}
+			if l.next[i] > 0 {
+				population++
+				hash = (hash ^ uint64(i)) * 1099511628211
+			}
+		}
+	}
+	l.age, l.next = l.next, l.age
+
+	settled := hash == l.history[0] || hash == l.history[1]
+	l.history[0], l.history[1] = l.history[1], hash
+	return population > 0 && !settled
+}
+
+func (l *Life) Render(ctx *c.RenderContext) image.Image {
+	if !l.seeded {
+		l.seed(ctx)
+	}
+	for n := l.steps(ctx.Now, lifeStepRate); n > 0; n-- {
+		if l.held > 0 {
+			l.held--
+			continue
+		}
+		if !l.step() && *l.Reseed {
+			l.seed(ctx)
+		}
+	}
+
+	l.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
+	l.Ctx.Clear()
+	dst := l.Ctx.Image().(*image.RGBA)
+	for y := 0; y < l.ComputedSizeY; y++ {
+		for x := 0; x < l.ComputedSizeX; x++ {
+			if age := l.age[y*l.ComputedSizeX+x]; age > 0 {
+				// fade from the end of the palette to a quarter of the way
+				// along as cells get older
+				v := 1 - math.Min(float64(age-1), 15)/15*0.75
+				dst.SetRGBA(x, y, l.palette.at(v))
+			}
+		}
+	}
+	return dst
+}
+
+func (l *Life) Stop() {
+	if l.Slot != nil {
+		l.Slot.Stop()
+	}
+	l.BaseComponent.Stop()
+}
+
+func init() {
+	c.RegisterComponent("life", func() c.Component { return &Life{} })
+}
@@ -0,0 +1,265 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/fogleman/gg"
+)
+
+const listFrameRate = 30 // render rate in milliseconds
+
+// List stacks its items top to bottom, showing as many as fit in its height
+// and moving through the rest every interval, e.g.
+//
+//	<list size-x="64" size-y="32" interval="3s">
+//		{{ range .Players }}<li justify="space-between"><text>{{ .Name }}</text><text>{{ .Points }}</text></li>{{ end }}
+//	</list>
+//
+// In page mode (the default) a page of items moves on at a time, in scroll
+// mode a single item. Each item is a template as wide as the list and as
+// tall as its content unless given a size-y. Once it gets to the end the
+// list starts over from the top. Without a size the list fits all its
+// items and doesn't move
+type List struct {
+	c.BaseComponent
+
+	XMLName       xml.Name      `xml:"list"`
+	Mode          string        `xml:"mode,attr"`           // page or scroll
+	Interval      string        `xml:"interval,attr"`       // time on each page or item, e.g. "4s"
+	Transition    string        `xml:"transition,attr"`     // none, slide or fade, fade is only for pages
+	TransitionDur string        `xml:"transition-dur,attr"` // how long moving on takes
+	Easing        string        `xml:"easing,attr"`
+	Gap           int           `xml:"gap,attr"` // space between items
+	Items         []*c.Template `xml:"li"`
+
+	heights    []int
+	pages      []int // the first item on each page
+	interval   time.Duration
+	transition time.Duration
+	ease       c.EasingFunc
+	start      time.Time
+}
+
+func (l *List) Init() {
+	l.Rr = listFrameRate
+	l.BaseComponent.Init()
+
+	l.Gap = int(math.Max(0, float64(l.Gap)))
+	l.heights = make([]int, len(l.Items))
+	for i, item := range l.Items {
+		if item.SizeX == "" && l.ComputedSizeX > 0 {
+			item.SizeX = strconv.Itoa(l.ComputedSizeX)
+		}
+		if item.BgColor == "" {
+			item.BgColor = "transparent"
+		}
+		item.SetParentSize(l.ComputedSizeX, l.ComputedSizeY)
+		item.Init()
+		if item.SizeX == "" || item.SizeY == "" {
+			w, h := contentSize(item)
+			if item.SizeX != "" {
+				w = item.ComputedSizeX
+			}
+			if item.SizeY != "" {
+				h = item.ComputedSizeY
+			}
+			item.Resize(w, h)
+		}
+		l.heights[i] = item.ComputedSizeY
+	}
+
+	// without a size the list is as wide as its widest item and tall enough
+	// for all of them
+	if l.SizeX == "" {
+		for _, item := range l.Items {
+			l.ComputedSizeX = int(math.Max(float64(l.ComputedSizeX), float64(item.ComputedSizeX)))
+		}
+	}
+	if l.SizeY == "" {
+		l.ComputedSizeY = int(math.Max(0, float64(l.total()-l.Gap)))
+	}
+	if l.Ctx == nil || l.Ctx.Width() != l.ComputedSizeX || l.Ctx.Height() != l.ComputedSizeY {
+		l.Ctx = gg.NewContext(l.ComputedSizeX, l.ComputedSizeY)
+	}
+
+	// fill each page with as many items as fit, always at least one
+	l.pages = l.pages[:0]
+	used := 0
+	for i, h := range l.heights {
+		if i == 0 || used+h > l.ComputedSizeY {
+			l.pages = append(l.pages, i)
+			used = 0
+		}
+		used += h + l.Gap
+	}
+
+	if l.Mode == "" {
+		l.Mode = "page"
+	}
+	if l.Transition == "" {
+		l.Transition = "slide"
+	}
+	if l.Easing == "" {
+		l.Easing = "ease-in-out"
+	}
+	l.ease = c.Easing(l.Easing)
+	l.interval = c.ParseDuration(l.Interval, 4*time.Second)
+	l.transition = c.ParseDuration(l.TransitionDur, 500*time.Millisecond)
+	if l.Transition == "none" || l.transition > l.interval {
+		l.transition = 0
+	}
+}
+
+// Reconcile carries on paging from where the list it replaces was, so
+// refreshed items don't send it back to the top
+func (l *List) Reconcile(prev c.Component) {
+	p, ok := prev.(*List)
+	if !ok {
+		prev.Stop()
+		return
+	}
+	for i, item := range p.Items {
+		if i < len(l.Items) {
+			l.Items[i].Reconcile(item)
+		} else {
+			item.Stop()
+		}
+	}
+	l.start = p.start
+	p.BaseComponent.Stop()
+}
+
+// step returns how many times the list has moved on after elapsed, and how
+// far through moving on to that one it is, from 0 to 1
+func (l *List) step(elapsed time.Duration) (int, float64) {
+	if l.interval <= 0 {
+		return 0, 1
+	}
+	step := int(elapsed / l.interval)
+	phase := elapsed % l.interval
+	if step == 0 || l.transition <= 0 || phase >= l.transition {
+		return step, 1
+	}
+	return step, l.ease(float64(phase) / float64(l.transition))
+}
+
+// total how tall all the items are stacked up, with a gap after the last
+// one so it loops round evenly
+func (l *List) total() int {
+	total := 0
+	for _, h := range l.heights {
+		total += h + l.Gap
+	}
+	return total
+}
+
+// drawFrom draws count items in a loop starting with item first, the top
+// of it at y, stopping early once the list is full
+func (l *List) drawFrom(dst draw.Image, ctx *c.RenderContext, first int, y int, count int) {
+	for i := first; i < first+count && y < l.ComputedSizeY; i++ {
+		item := l.Items[i%len(l.Items)]
+		if y+l.heights[i%len(l.Items)] > 0 && item.ComputedSizeX > 0 && item.ComputedSizeY > 0 {
+			c.Composite(dst, item.Render(ctx), image.Pt(0, y), item.DrawState(ctx.Now))
+		}
+		y += l.heights[i%len(l.Items)] + l.Gap
+	}
+}
+
+// pageSize how many items are on the page starting with item first
+func (l *List) pageSize(first int) int {
+	for _, start := range l.pages {
+		if start > first {
+			return start - first
+		}
+	}
+	return len(l.Items) - first
+}
+
+func (l *List) Render(ctx *c.RenderContext) image.Image {
+	l.Ctx.SetColor(color.Transparent)
+	l.Ctx.Clear()
+	if len(l.Items) == 0 || l.total() <= 0 {
+		return l.Ctx.Image()
+	}
+	dst := l.Ctx.Image().(draw.Image)
+
+	if l.start.IsZero() {
+		l.start = ctx.Now
+	}
+	step, progress := l.step(ctx.Now.Sub(l.start))
+
+	if l.Mode == "scroll" {
+		total := l.total()
+		if total-l.Gap <= l.ComputedSizeY {
+			l.drawFrom(dst, ctx, 0, 0, len(l.Items))
+			return l.Ctx.Image()
+		}
+		// where the top of each item is, carrying on past the end
+		top := func(i int) int {
+			y := (i / len(l.Items)) * total
+			for j := 0; j < i%len(l.Items); j++ {
+				y += l.heights[j] + l.Gap
+			}
+			return y
+		}
+		offset := top(step)
+		if progress < 1 {
+			offset = top(step-1) + int(progress*float64(offset-top(step-1)))
+		}
+		// find the item the offset lands in and draw down from it
+		first := (offset / total) * len(l.Items)
+		for top(first+1) <= offset {
+			first++
+		}
+		// the items are taller than the list, two rounds always fill it
+		l.drawFrom(dst, ctx, first, top(first)-offset, 2*len(l.Items))
+		return l.Ctx.Image()
+	}
+
+	if len(l.pages) == 1 {
+		l.drawFrom(dst, ctx, 0, 0, len(l.Items))
+		return l.Ctx.Image()
+	}
+	current := l.pages[step%len(l.pages)]
+	if progress >= 1 {
+		l.drawFrom(dst, ctx, current, 0, l.pageSize(current))
+		return l.Ctx.Image()
+	}
+
+	before := l.pages[(step-1)%len(l.pages)]
+	h := l.ComputedSizeY
+	switch l.Transition {
+	case "fade":
+		l.drawFrom(dst, ctx, before, 0, l.pageSize(before))
+		next := image.NewRGBA(dst.Bounds())
+		l.drawFrom(next, ctx, current, 0, l.pageSize(current))
+		alpha := image.NewUniform(color.Alpha{uint8(progress * 255)})
+		// fade the old page out as the new one fades in
+		draw.DrawMask(dst, dst.Bounds(), image.Transparent, image.Point{}, alpha, image.Point{}, draw.Src)
+		draw.DrawMask(dst, dst.Bounds(), next, image.Point{}, alpha, image.Point{}, draw.Over)
+	default:
+		// the new page pushes the old one up and out
+		moved := int(progress * float64(h))
+		l.drawFrom(dst, ctx, before, -moved, l.pageSize(before))
+		l.drawFrom(dst, ctx, current, h-moved, l.pageSize(current))
+	}
+	return l.Ctx.Image()
+}
+
+func (l *List) Stop() {
+	for _, item := range l.Items {
+		item.Stop()
+	}
+	l.BaseComponent.Stop()
+}
+
+func init() {
+	c.RegisterComponent("list", func() c.Component { return &List{} })
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/component/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	golden.Fonts(t)
+	parse := func(src string) *List {
+		tmpl := &c.Template{}
+		require.NoError(t, xml.Unmarshal([]byte(`<template size-x="64" size-y="32">`+src+`</template>`), tmpl))
+		tmpl.Init()
+		t.Cleanup(tmpl.Stop)
+		return tmpl.Components[0].(*List)
+	}
+	clock := c.NewClock(golden.Start, golden.Seed)
+
+	// blank items, e.g. from ranging over empty data, draw nothing
+	for _, mode := range []string{"page", "scroll"} {
+		l := parse(`<list size-x="16" size-y="8" mode="` + mode + `" gap="-2"><li></li><li></li></list>`)
+		assert.Equal(t, 0, l.Gap)
+		for _, at := range []time.Duration{0, 5 * time.Second} {
+			im := l.Render(clock.At(at))
+			assert.Equal(t, 16, im.Bounds().Dx(), mode)
+		}
+	}
+
+	// without a size it fits its items
+	l := parse(`<list gap="1">
+		<li><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Eagles</text></li>
+		<li><text font="Go" style="Regular" size="7" color="#FFFFFFFF">Cowboys</text></li>
+	</list>`)
+	assert.Equal(t, l.Items[1].Width(), l.Width())
+	assert.Equal(t, l.Items[0].Height()+1+l.Items[1].Height(), l.Height())
+	assert.Len(t, l.pages, 1)
+	im := l.Render(clock.At(0))
+	assert.Equal(t, l.Width(), im.Bounds().Dx())
+}
@@ -5,7 +5,6 @@ import (
 	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
 	"image"
 	"image/color"
-	"math/rand"
 )
 
 type Drop struct {
@@ -22,16 +21,19 @@ type MatrixRain struct {
 func (mr *MatrixRain) Init() {
 	mr.BaseComponent.Init()
 	mr.NumDrops = 100
-	for i := 0; i < mr.NumDrops; i++ {
-		mr.Drops = append(mr.Drops, Drop{rand.Float64() * float64(mr.Width()), rand.Float64() * float64(mr.Height()), rand.Float64()*5 + 1})
-	}
 }
 
-func (mr *MatrixRain) Render() image.Image {
+func (mr *MatrixRain) Render(ctx *c.RenderContext) image.Image {
 	mr.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	mr.Ctx.Clear()
+	// drops are placed on the first frame, from the frame's randomness
+	if mr.Drops == nil {
+		for i := 0; i < mr.NumDrops; i++ {
+			mr.Drops = append(mr.Drops, Drop{ctx.Rand.Float64() * float64(mr.Width()), ctx.Rand.Float64() * float64(mr.Height()), ctx.Rand.Float64()*5 + 1})
+		}
+	}
 	for _, drop := range mr.Drops {
-		char := rune(33 + rand.Intn(94)) // Select a random ASCII character
+		char := rune(33 + ctx.Rand.Intn(94)) // Select a random ASCII character
 		drop.y += drop.speed
 		if drop.y > float64(mr.Height()) {
 			drop.y = 0
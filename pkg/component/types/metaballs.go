@@ -0,0 +1,86 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"math"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+// Metaballs blobs drifting around and melting into each other where they
+// meet, colored through the palette by how strong the field is
+type Metaballs struct {
+	c.BaseComponent
+	effect
+
+	XMLName xml.Name `xml:"metaballs"`
+	Count   int      `xml:"count,attr"`  // how many balls, defaults to 4
+	Radius  float64  `xml:"radius,attr"` // size of each ball in pixels, defaults to a quarter of the height
+	balls   []ball
+	prev    float64
+}
+
+type ball struct {
+	x, y, vx, vy float64 // pixels and pixels per second
+}
+
+func (m *Metaballs) Init() {
+	m.Rr = effectFrameRate
+	m.BaseComponent.Init()
+	m.initEffect("ocean")
+	if m.Count <= 0 {
+		m.Count = 4
+	}
+	if m.Radius <= 0 {
+		m.Radius = float64(m.ComputedSizeY) / 4
+	}
+}
+
+func (m *Metaballs) Render(ctx *c.RenderContext) image.Image {
+	w, h := float64(m.ComputedSizeX), float64(m.ComputedSizeY)
+	if m.balls == nil {
+		for i := 0; i < m.Count; i++ {
+			angle := ctx.Rand.Float64() * 2 * math.Pi
+			speed := h / 4 * (0.5 + ctx.Rand.Float64())
+			m.balls = append(m.balls, ball{ctx.Rand.Float64() * w, ctx.Rand.Float64() * h, math.Cos(angle) * speed, math.Sin(angle) * speed})
+		}
+	}
+
+	t := m.elapsed(ctx.Now)
+	dt := t - m.prev
+	m.prev = t
+	for i := range m.balls {
+		b := &m.balls[i]
+		b.x += b.vx * dt
+		b.y += b.vy * dt
+		if b.x < 0 || b.x > w {
+			b.vx = -b.vx
+			b.x = math.Max(0, math.Min(w, b.x))
+		}
+		if b.y < 0 || b.y > h {
+			b.vy = -b.vy
+			b.y = math.Max(0, math.Min(h, b.y))
+		}
+	}
+
+	dst := m.Ctx.Image().(*image.RGBA)
+	r2 := m.Radius * m.Radius
+	for y := 0; y < m.ComputedSizeY; y++ {
+		for x := 0; x < m.ComputedSizeX; x++ {
+			var field float64
+			for _, b := range m.balls {
+				dx, dy := float64(x)+0.5-b.x, float64(y)+0.5-b.y
+				field += r2 / (dx*dx + dy*dy + 1)
+			}
+			// the surface of a lone ball is where the field is 1, fade in
+			// a little outside it and saturate inside
+			dst.SetRGBA(x, y, m.palette.at((field-0.4)/1.6))
+		}
+	}
+	return dst
+}
+
+func init() {
+	c.RegisterComponent("metaballs", func() c.Component { return &Metaballs{} })
+}
@@ -11,7 +11,7 @@ import (
 type PaddleBallVisualizer struct {
 	c.BaseComponent
 
-	XMLName      xml.Name `xml:"pong"`
+	XMLName      xml.Name `xml:"paddleball"`
 	BallRadius   float64  `xml:"ballRadius,attr"`
 	BallSpeedX   float64
 	BallSpeedY   float64
@@ -38,7 +38,7 @@ func (pbv *PaddleBallVisualizer) Init() {
 	pbv.BallY = float64(pbv.Height()) / 2
 }
 
-func (pbv *PaddleBallVisualizer) Render() image.Image {
+func (pbv *PaddleBallVisualizer) Render(ctx *c.RenderContext) image.Image {
 	pbv.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	pbv.Ctx.Clear()
 	// Move the ball
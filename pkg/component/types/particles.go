@@ -34,19 +34,6 @@ type GravityParticles struct {
 func (gp *GravityParticles) Init() {
 	gp.BaseComponent.Init()
 
-	// Sample initialization for particles and gravity points
-	// In a real-world scenario, these could be populated based on the music or other parameters
-	for i := 0; i < 50; i++ {
-		gp.Particles = append(gp.Particles, Particle{
-			X:      float64(rand.Intn(gp.Width())),
-			Y:      float64(rand.Intn(gp.Height())),
-			SpeedX: float64(rand.Intn(5) - 2), // Random speed between -2 and 2
-			SpeedY: float64(rand.Intn(5) - 2),
-			Color:  color.RGBA{uint8(rand.Intn(256)), uint8(rand.Intn(256)), uint8(rand.Intn(256)), 255},
-			Radius: 2,
-		})
-	}
-
 	gp.GravityPoints = append(gp.GravityPoints, GravityParticle{
 		X:     float64(gp.Width()) / 2,
 		Y:     float64(gp.Height()) / 2,
@@ -55,10 +42,29 @@ func (gp *GravityParticles) Init() {
 	})
 }
 
-func (gp *GravityParticles) Render() image.Image {
+// scatter places the particles at random
+func (gp *GravityParticles) scatter(rnd *rand.Rand) {
+	for i := 0; i < 50; i++ {
+		gp.Particles = append(gp.Particles, Particle{
+			X:      float64(rnd.Intn(gp.Width())),
+			Y:      float64(rnd.Intn(gp.Height())),
+			SpeedX: float64(rnd.Intn(5) - 2), // Random speed between -2 and 2
+			SpeedY: float64(rnd.Intn(5) - 2),
+			Color:  color.RGBA{uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), uint8(rnd.Intn(256)), 255},
+			Radius: 2,
+		})
+	}
+}
+
+func (gp *GravityParticles) Render(ctx *c.RenderContext) image.Image {
 	gp.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	gp.Ctx.Clear()
 
+	// particles are scattered on the first frame, from the frame's randomness
+	if gp.Particles == nil {
+		gp.scatter(ctx.Rand)
+	}
+
 	// Update and render particles
 	for i, particle := range gp.Particles {
 		for _, g := range gp.GravityPoints {
@@ -66,6 +72,10 @@ func (gp *GravityParticles) Render() image.Image {
 			distX := g.X - particle.X
 			distY := g.Y - particle.Y
 			distance := math.Sqrt(distX*distX + distY*distY)
+			if distance == 0 {
+				// sitting on the point, there's no direction to pull in
+				continue
+			}
 
 			// The closer the particle is to the gravity point, the stronger the pull
 			force := g.Force / (distance + 1) // +1 to avoid division by zero
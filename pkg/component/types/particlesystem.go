@@ -0,0 +1,381 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+const (
+	particleFrameRate = 30 // render rate in milliseconds
+	particleMaxStep   = 100 * time.Millisecond
+)
+
+// Particles is a general particle system. Emitters spawn particles that
+// move under the forces, change color over their life and die when it runs
+// out. An emitter with source="template" breaks the child template into
+// one particle per pixel instead, e.g. for text dissolving into sparks
+type Particles struct {
+	c.BaseComponent
+
+	XMLName  xml.Name           `xml:"particles"`
+	Max      int                `xml:"max,attr"`    // most particles alive at once, defaults to 1000
+	Bounds   string             `xml:"bounds,attr"` // none (default), bounce, wrap or kill
+	Bounce   *float64           `xml:"bounce,attr"` // share of speed kept after bouncing, defaults to 0.8
+	Emitters []*ParticleEmitter `xml:"emitter"`
+	Forces   []*ParticleForce   `xml:"force"`
+	Slot     *c.Template        `xml:"template"` // broken into particles by source emitters
+
+	particles []particle
+	last      time.Time
+	pixels    []sourcePixel // source pixels waiting to be released
+	captured  bool
+}
+
+// ParticleEmitter spawns particles from a point or area, or from the pixels
+// of the child template when Source is "template"
+type ParticleEmitter struct {
+	X        float64  `xml:"x,attr"`
+	Y        float64  `xml:"y,attr"`
+	Width    float64  `xml:"width,attr"`  // spawn anywhere in this area from x, y
+	Height   float64  `xml:"height,attr"` // rather than at the point
+	Rate     float64  `xml:"rate,attr"`   // particles per second, or source pixels released per second
+	Burst    int      `xml:"burst,attr"`  // particles spawned at once when the emitter starts
+	Delay    string   `xml:"delay,attr"`  // how long to wait before starting
+	Duration string   `xml:"duration,attr"`
+	Angle    float64  `xml:"angle,attr"`  // direction in degrees, 0 is right and -90 up
+	Spread   *float64 `xml:"spread,attr"` // degrees either side of the angle, defaults to 180 for every direction
+	Speed    float64  `xml:"speed,attr"`  // pixels per second
+	SpeedVar float64  `xml:"speed-var,attr"`
+	Life     string   `xml:"life,attr"` // how long particles live, defaults to 2s
+	LifeVar  string   `xml:"life-var,attr"`
+	Size     float64  `xml:"size,attr"`   // radius in pixels, a single pixel when 0
+	Colors   string   `xml:"colors,attr"` // colors over life, e.g. "#FFFF00FF,#FF000000"
+	Source   string   `xml:"source,attr"` // "template" to spawn from the child template's pixels
+	Order    string   `xml:"order,attr"`  // source pixel release order, left (default), right, top, bottom or random
+
+	delay, duration, life, lifeVar time.Duration
+	gradient                       []color.NRGBA
+	started                        time.Time
+	owed                           float64 // particles due but not spawned yet
+	burst                          bool
+}
+
+// ParticleForce accelerates every particle
+type ParticleForce struct {
+	Type     string  `xml:"type,attr"` // gravity (default), wind, attractor or drag
+	X        float64 `xml:"x,attr"`    // acceleration in pixels per second², or the attractor's position
+	Y        float64 `xml:"y,attr"`
+	Strength float64 `xml:"strength,attr"` // attractor pull, negative to repel, or drag per second
+	Radius   float64 `xml:"radius,attr"`   // attractor reach, 0 for everywhere
+	Gust     float64 `xml:"gust,attr"`     // random variation added to the wind
+}
+
+type particle struct {
+	x, y, vx, vy float64
+	age, life    float64 // seconds
+	size         float64
+	gradient     []color.NRGBA
+}
+
+// sourcePixel is a pixel of the source template waiting to be released
+type sourcePixel struct {
+	x, y  int
+	color color.NRGBA
+}
+
+func (p *Particles) Init() {
+	p.Rr = particleFrameRate
+	p.BaseComponent.Init()
+
+	if p.Max <= 0 {
+		p.Max = 1000
+	}
+	if p.Bounce == nil {
+		bounce := 0.8
+		p.Bounce = &bounce
+	}
+	for _, e := range p.Emitters {
+		e.init()
+	}
+	for _, f := range p.Forces {
+		if f.Type == "" {
+			f.Type = "gravity"
+		}
+		if f.Type == "gravity" && f.X == 0 && f.Y == 0 {
+			f.Y = 30
+		}
+	}
+	if p.Slot != nil {
+		p.Slot.SetParentSize(p.ComputedSizeX, p.ComputedSizeY)
+		p.Slot.Init()
+	}
+}
+
+func (e *ParticleEmitter) init() {
+	e.delay = c.ParseDuration(e.Delay, 0)
+	e.duration = c.ParseDuration(e.Duration, 0)
+	e.life = c.ParseDuration(e.Life, 2*time.Second)
+	e.lifeVar = c.ParseDuration(e.LifeVar, 0)
+	if e.Spread == nil {
+		spread := 180.0
+		e.Spread = &spread
+	}
+	if e.Colors != "" {
+		gradient, err := colors.ParseList(e.Colors)
+		if err != nil {
+			log.Printf("Invalid emitter colors: %v", err)
+		}
+		e.gradient = gradient
+	}
+	if len(e.gradient) == 0 && e.Source == "" {
+		e.gradient = []color.NRGBA{{255, 255, 255, 255}}
+	}
+}
+
+// due returns how many particles the emitter should spawn for a step of dt
+// ending at now
+func (e *ParticleEmitter) due(now time.Time, dt float64) int {
+	if e.started.IsZero() {
+		e.started = now
+	}
+	running := now.Sub(e.started) - e.delay
+	if running < 0 || (e.duration > 0 && running > e.duration) {
+		return 0
+	}
+	n := 0
+	if !e.burst {
+		e.burst = true
+		n += e.Burst
+	}
+	e.owed += e.Rate * dt
+	n += int(e.owed)
+	e.owed -= math.Floor(e.owed)
+	return n
+}
+
+// spawn creates a particle at x, y heading off in the emitter's direction
+func (e *ParticleEmitter) spawn(rnd *rand.Rand, x float64, y float64) particle {
+	angle := (e.Angle + (rnd.Float64()*2-1)**e.Spread) * math.Pi / 180
+	speed := e.Speed + (rnd.Float64()*2-1)*e.SpeedVar
+	life := e.life + time.Duration((rnd.Float64()*2-1)*float64(e.lifeVar))
+	return particle{
+		x:        x,
+		y:        y,
+		vx:       math.Cos(angle) * speed,
+		vy:       math.Sin(angle) * speed,
+		life:     math.Max(life.Seconds(), 0.01),
+		size:     e.Size,
+		gradient: e.gradient,
+	}
+}
+
+// capture renders the source template and queues its pixels for release,
+// skipping transparent ones and the template's background
+func (p *Particles) capture(ctx *c.RenderContext) {
+	im := p.Slot.Render(ctx)
+	bg := color.NRGBAModel.Convert(c.ParseBgColor(p.Slot.BgColor)).(color.NRGBA)
+	b := im.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			px := color.NRGBAModel.Convert(im.At(x, y)).(color.NRGBA)
+			if px.A == 0 || px == bg {
+				continue
+			}
+			p.pixels = append(p.pixels, sourcePixel{x - b.Min.X, y - b.Min.Y, px})
+		}
+	}
+	p.captured = true
+
+	order := ""
+	for _, e := range p.Emitters {
+		if e.Source == "template" {
+			order = e.Order
+			break
+		}
+	}
+	sortPixels(p.pixels, order, ctx.Rand)
+}
+
+// sortPixels puts source pixels in the order they're released
+func sortPixels(pixels []sourcePixel, order string, rnd *rand.Rand) {
+	key := func(px sourcePixel) int { return px.x }
+	switch order {
+	case "", "left":
+	case "right":
+		key = func(px sourcePixel) int { return -px.x }
+	case "top":
+		key = func(px sourcePixel) int { return px.y }
+	case "bottom":
+		key = func(px sourcePixel) int { return -px.y }
+	case "random":
+		rnd.Shuffle(len(pixels), func(i, j int) { pixels[i], pixels[j] = pixels[j], pixels[i] })
+		return
+	default:
+		log.Printf("Invalid particle order '%s'", order)
+	}
+	sort.SliceStable(pixels, func(i, j int) bool { return key(pixels[i]) < key(pixels[j]) })
+}
+
+// emit spawns whatever each emitter owes for a step of dt
+func (p *Particles) emit(ctx *c.RenderContext, dt float64) {
+	for _, e := range p.Emitters {
+		n := e.due(ctx.Now, dt)
+		if e.Source == "template" {
+			if e.Rate <= 0 && e.Burst == 0 && ctx.Now.Sub(e.started) >= e.delay {
+				n = len(p.pixels) // release everything at once
+			}
+			for ; n > 0 && len(p.pixels) > 0 && len(p.particles) < p.Max; n-- {
+				px := p.pixels[0]
+				p.pixels = p.pixels[1:]
+				pt := e.spawn(ctx.Rand, float64(px.x)+0.5, float64(px.y)+0.5)
+				// fade from the pixel's own color into the emitter's colors
+				pt.gradient = append([]color.NRGBA{px.color}, e.gradient...)
+				p.particles = append(p.particles, pt)
+			}
+			continue
+		}
+		for ; n > 0 && len(p.particles) < p.Max; n-- {
+			x := e.X + ctx.Rand.Float64()*e.Width
+			y := e.Y + ctx.Rand.Float64()*e.Height
+			p.particles = append(p.particles, e.spawn(ctx.Rand, x, y))
+		}
+	}
+}
+
+// step moves every particle on by dt seconds and drops the dead ones
+func (p *Particles) step(rnd *rand.Rand, dt float64) {
+	w, h := float64(p.ComputedSizeX), float64(p.ComputedSizeY)
+	alive := p.particles[:0]
+	for _, pt := range p.particles {
+		pt.age += dt
+		if pt.age >= pt.life {
+			continue
+		}
+
+		var ax, ay float64
+		for _, f := range p.Forces {
+			switch f.Type {
+			case "gravity":
+				ax += f.X
+				ay += f.Y
+			case "wind":
+				ax += f.X + (rnd.Float64()*2-1)*f.Gust
+				ay += f.Y + (rnd.Float64()*2-1)*f.Gust
+			case "attractor":
+				dx, dy := f.X-pt.x, f.Y-pt.y
+				dist := math.Hypot(dx, dy)
+				if dist < 1 || (f.Radius > 0 && dist > f.Radius) {
+					continue
+				}
+				ax += f.Strength * dx / (dist * dist)
+				ay += f.Strength * dy / (dist * dist)
+			case "drag":
+				pt.vx -= pt.vx * math.Min(1, f.Strength*dt)
+				pt.vy -= pt.vy * math.Min(1, f.Strength*dt)
+			}
+		}
+		pt.vx += ax * dt
+		pt.vy += ay * dt
+		pt.x += pt.vx * dt
+		pt.y += pt.vy * dt
+
+		switch p.Bounds {
+		case "bounce":
+			if pt.x < 0 || pt.x >= w {
+				pt.x = math.Max(0, math.Min(w-0.01, pt.x))
+				pt.vx = -pt.vx * *p.Bounce
+			}
+			if pt.y < 0 || pt.y >= h {
+				pt.y = math.Max(0, math.Min(h-0.01, pt.y))
+				pt.vy = -pt.vy * *p.Bounce
+			}
+		case "wrap":
+			pt.x = math.Mod(math.Mod(pt.x, w)+w, w)
+			pt.y = math.Mod(math.Mod(pt.y, h)+h, h)
+		case "kill":
+			if pt.x < 0 || pt.x >= w || pt.y < 0 || pt.y >= h {
+				continue
+			}
+		}
+		alive = append(alive, pt)
+	}
+	p.particles = alive
+}
+
+// colorAt returns the particle's color part way through its life
+func (pt *particle) colorAt() color.NRGBA {
+	stops := pt.gradient
+	if len(stops) == 1 {
+		return stops[0]
+	}
+	pos := pt.age / pt.life * float64(len(stops)-1)
+	i := int(pos)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	t := pos - float64(i)
+	lerp := func(a, b uint8) uint8 { return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t)) }
+	from, to := stops[i], stops[i+1]
+	return color.NRGBA{lerp(from.R, to.R), lerp(from.G, to.G), lerp(from.B, to.B), lerp(from.A, to.A)}
+}
+
+func (p *Particles) Render(ctx *c.RenderContext) image.Image {
+	if p.Slot != nil && !p.captured {
+		p.capture(ctx)
+	}
+
+	// step by the time since the last frame, in bounded steps so a stall
+	// doesn't fling everything off screen
+	var dt float64
+	if !p.last.IsZero() {
+		dt = math.Min(ctx.Now.Sub(p.last).Seconds(), particleMaxStep.Seconds())
+	}
+	p.last = ctx.Now
+	p.step(ctx.Rand, dt)
+	p.emit(ctx, dt)
+
+	p.Ctx.SetColor(color.Transparent)
+	p.Ctx.Clear()
+	dst := p.Ctx.Image().(*image.RGBA)
+	// source pixels not released yet stay where they are
+	for _, px := range p.pixels {
+		dst.Set(px.x, px.y, px.color)
+	}
+	for i := range p.particles {
+		pt := &p.particles[i]
+		col := pt.colorAt()
+		if col.A == 0 {
+			continue
+		}
+		if pt.size <= 0 {
+			at := image.Rect(0, 0, 1, 1).Add(image.Pt(int(math.Floor(pt.x)), int(math.Floor(pt.y))))
+			draw.Draw(dst, at, image.NewUniform(col), image.Point{}, draw.Over)
+			continue
+		}
+		p.Ctx.SetColor(col)
+		p.Ctx.DrawCircle(pt.x, pt.y, pt.size)
+		p.Ctx.Fill()
+	}
+	return p.Ctx.Image()
+}
+
+func (p *Particles) Stop() {
+	if p.Slot != nil {
+		p.Slot.Stop()
+	}
+	p.BaseComponent.Stop()
+}
+
+func init() {
+	c.RegisterComponent("particles", func() c.Component { return &Particles{} })
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"math"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+// Plasma the classic demo plasma, overlapping sine waves colored through
+// the palette
+type Plasma struct {
+	c.BaseComponent
+	effect
+
+	XMLName xml.Name `xml:"plasma"`
+	Scale   float64  `xml:"scale,attr"` // size of the blobs in pixels, defaults to 8
+}
+
+func (p *Plasma) Init() {
+	p.Rr = effectFrameRate
+	p.BaseComponent.Init()
+	p.initEffect("rainbow")
+	if p.Scale <= 0 {
+		p.Scale = 8
+	}
+}
+
+func (p *Plasma) Render(ctx *c.RenderContext) image.Image {
+	t := p.elapsed(ctx.Now)
+	dst := p.Ctx.Image().(*image.RGBA)
+	cx := float64(p.ComputedSizeX) / 2
+	cy := float64(p.ComputedSizeY) / 2
+	for y := 0; y < p.ComputedSizeY; y++ {
+		for x := 0; x < p.ComputedSizeX; x++ {
+			fx, fy := float64(x)/p.Scale, float64(y)/p.Scale
+			v := math.Sin(fx + t)
+			v += math.Sin((fy + t) / 2)
+			v += math.Sin((fx + fy + t) / 2)
+			dx, dy := (float64(x)-cx)/p.Scale+math.Sin(t/3)*2, (float64(y)-cy)/p.Scale+math.Cos(t/2)*2
+			v += math.Sin(math.Sqrt(dx*dx+dy*dy+1) + t)
+			// v is -4 to 4, go round the palette once
+			dst.SetRGBA(x, y, p.palette.at((v+4)/8))
+		}
+	}
+	return dst
+}
+
+func init() {
+	c.RegisterComponent("plasma", func() c.Component { return &Plasma{} })
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"time"
+)
+
+// playback steps through the frames of an animation, honoring each frame's
+// delay. It's shared by the components that play frame based animations
+type playback struct {
+	delays   []time.Duration
+	loops    int   // how many times to play, 0 for forever
+	sequence []int // order frames are shown in during one play
+	last     int   // frame held once the plays are done
+	cycle    time.Duration
+	start    time.Time
+}
+
+// init works out the order frames are shown in for the given play mode
+// (loop, once, ping-pong or static) and returns the shortest frame delay,
+// which is how often the component needs to render. Zero is returned when
+// there's nothing to animate
+func (p *playback) init(delays []time.Duration, mode string, loops int) time.Duration {
+	p.delays = delays
+	p.loops = loops
+	p.sequence = []int{0}
+	p.last = 0
+	if len(delays) < 2 || mode == "static" {
+		return 0
+	}
+
+	p.sequence = p.sequence[:0]
+	for ix := range delays {
+		p.sequence = append(p.sequence, ix)
+	}
+	p.last = len(delays) - 1
+	if mode == "ping-pong" {
+		for ix := len(delays) - 2; ix > 0; ix-- {
+			p.sequence = append(p.sequence, ix)
+		}
+		// a play ends back where it started
+		p.last = 0
+	}
+	if mode == "once" {
+		p.loops = 1
+	}
+
+	shortest := delays[0]
+	for _, ix := range p.sequence {
+		p.cycle += delays[ix]
+		if delays[ix] < shortest {
+			shortest = delays[ix]
+		}
+	}
+	return shortest
+}
+
+// frame returns the frame to show at now, the animation starts on the
+// first frame it's asked for
+func (p *playback) frame(now time.Time) int {
+	if p.start.IsZero() {
+		p.start = now
+	}
+	return p.frameAt(now.Sub(p.start))
+}
+
+// frameAt returns the frame to show after the animation has played for elapsed
+func (p *playback) frameAt(elapsed time.Duration) int {
+	if len(p.sequence) == 1 || p.cycle <= 0 {
+		return p.sequence[0]
+	}
+	if p.loops > 0 && elapsed >= p.cycle*time.Duration(p.loops) {
+		// finished, hold the frame it ended on
+		return p.last
+	}
+	phase := elapsed % p.cycle
+	for _, ix := range p.sequence {
+		if phase < p.delays[ix] {
+			return ix
+		}
+		phase -= p.delays[ix]
+	}
+	return p.sequence[len(p.sequence)-1]
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayback(t *testing.T) {
+	delays := []time.Duration{100 * ms, 100 * ms, 100 * ms}
+
+	tests := []struct {
+		mode  string
+		loops int
+		at    []time.Duration
+		want  []int
+	}{
+		{"loop", 0, []time.Duration{0, 150 * ms, 250 * ms, 300 * ms, 1050 * ms}, []int{0, 1, 2, 0, 1}},
+		{"once", 0, []time.Duration{0, 250 * ms, 300 * ms, time.Hour}, []int{0, 2, 2, 2}},
+		{"loop", 2, []time.Duration{350 * ms, 600 * ms, time.Hour}, []int{0, 2, 2}},
+		{"ping-pong", 0, []time.Duration{0, 250 * ms, 350 * ms, 400 * ms}, []int{0, 2, 1, 0}},
+		{"static", 0, []time.Duration{0, 150 * ms, time.Hour}, []int{0, 0, 0}},
+	}
+	for _, tt := range tests {
+		var p playback
+		p.init(delays, tt.mode, tt.loops)
+		for i, at := range tt.at {
+			assert.Equal(t, tt.want[i], p.frameAt(at), "%s %d at %v", tt.mode, tt.loops, at)
+		}
+	}
+
+	// a ping-pong played a set number of times ends back on its first frame
+	var p playback
+	p.init(delays, "ping-pong", 1)
+	assert.Equal(t, 1, p.frameAt(350*ms))
+	assert.Equal(t, 0, p.frameAt(400*ms))
+	assert.Equal(t, 0, p.frameAt(time.Hour))
+}
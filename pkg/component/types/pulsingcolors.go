@@ -27,7 +27,7 @@ func (pc *PulsingCircles) Init() {
 	}
 }
 
-func (pc *PulsingCircles) Render() image.Image {
+func (pc *PulsingCircles) Render(ctx *c.RenderContext) image.Image {
 	pc.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	pc.Ctx.Clear()
 
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+)
+
+// QR renders text or a URL as a QR code, scaled up by the largest whole
+// number of pixels per module that fits and centered in the component
+type QR struct {
+	c.BaseComponent
+
+	XMLName   xml.Name   `xml:"qr"`
+	Data      string     `xml:"data,attr"`
+	Level     string     `xml:"level,attr"`      // error correction, L, M (default), Q or H
+	QuietZone *int       `xml:"quiet-zone,attr"` // light modules around the code, defaults to 4
+	Color     *util.RGBA `xml:"color,attr"`      // dark modules, defaults to black
+	BgColor   *util.RGBA `xml:"bg-color,attr"`   // light modules and the quiet zone, defaults to white
+	img       *image.RGBA
+}
+
+func (q *QR) Init() {
+	q.Rr = -1
+	q.BaseComponent.Init()
+
+	level, err := util.ParseQRLevel(q.Level)
+	if err != nil {
+		log.Fatal(err)
+	}
+	code, err := util.EncodeQR([]byte(q.Data), level)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	quiet := 4
+	if q.QuietZone != nil {
+		quiet = *q.QuietZone
+	}
+	dark, light := color.RGBA{0, 0, 0, 255}, color.RGBA{255, 255, 255, 255}
+	if q.Color != nil {
+		dark = q.Color.RGBA
+	}
+	if q.BgColor != nil {
+		light = q.BgColor.RGBA
+	}
+
+	modules := code.Size + quiet*2
+	scale := q.ComputedSizeX
+	if q.ComputedSizeY < scale {
+		scale = q.ComputedSizeY
+	}
+	scale /= modules
+	if scale < 1 {
+		log.Printf("QR code needs %dx%d pixels but only has %dx%d, it will be cropped", modules, modules, q.ComputedSizeX, q.ComputedSizeY)
+		scale = 1
+	}
+
+	// center the code, quiet zone included
+	side := modules * scale
+	x0 := (q.ComputedSizeX-side)/2 + quiet*scale
+	y0 := (q.ComputedSizeY-side)/2 + quiet*scale
+
+	q.img = image.NewRGBA(image.Rect(0, 0, q.ComputedSizeX, q.ComputedSizeY))
+	zone := image.Rect(0, 0, side, side).Add(image.Pt(x0-quiet*scale, y0-quiet*scale))
+	draw.Draw(q.img, zone, image.NewUniform(light), image.Point{}, draw.Src)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Dark(x, y) {
+				module := image.Rect(0, 0, scale, scale).Add(image.Pt(x0+x*scale, y0+y*scale))
+				draw.Draw(q.img, module, image.NewUniform(dark), image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+func (q *QR) Render(ctx *c.RenderContext) image.Image {
+	return q.img
+}
+
+func init() {
+	c.RegisterComponent("qr", func() c.Component { return &QR{} })
+}
@@ -2,11 +2,10 @@ package types
 
 import (
 	"encoding/xml"
-	"fmt"
 	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
 	"github.com/6ixisgood/matrix-ticker/pkg/util"
 	"github.com/fogleman/gg"
-	"github.com/golang/freetype/truetype"
+	fontpkg "golang.org/x/image/font"
 	"image"
 	"image/color"
 	"math"
@@ -15,6 +14,7 @@ import (
 // Rainbow text
 type AnimatedRainbowText struct {
 	c.BaseComponent
+	textEffects
 
 	XMLName    xml.Name `xml:"rainbow-text"`
 	Font       string   `xml:"font,attr"`
@@ -22,32 +22,36 @@ type AnimatedRainbowText struct {
 	FontSize   float64  `xml:"size,attr"`
 	Text       string   `xml:",chardata"`
 	colorIndex int
+	face       fontpkg.Face
 }
 
 func (art *AnimatedRainbowText) Init() {
 	art.BaseComponent.Init()
 	art.Ctx = gg.NewContext(0, 0)
 
+	art.face = util.LoadFace(util.FontName(art.Font, art.FontStyle), art.FontSize)
+	art.Ctx.SetFontFace(art.face)
+
+	art.initEffects()
+
 	// get the size of the string
 	w, h := art.Ctx.MeasureString(art.Text)
 	w_i := int(math.Ceil(w))
 	h_i := int(math.Ceil(h))
+	margin, marginY := art.margin(), art.marginY()
 	if art.ComputedSizeX == 0 {
-		art.ComputedSizeX = w_i
+		art.ComputedSizeX = w_i + 2*margin
 	}
 	if art.ComputedSizeY == 0 {
-		art.ComputedSizeY = h_i
+		art.ComputedSizeY = h_i + 2*marginY
 	}
 
 	// resize context
 	art.Ctx = gg.NewContext(art.ComputedSizeX, art.ComputedSizeY)
-
-	var font = util.LoadFont(fmt.Sprintf("%s-%s", art.Font, art.FontStyle))
-	var face = truetype.NewFace(font, &truetype.Options{Size: art.FontSize})
-	art.Ctx.SetFontFace(face)
+	art.Ctx.SetFontFace(art.face)
 }
 
-func (art *AnimatedRainbowText) Render() image.Image {
+func (art *AnimatedRainbowText) Render(ctx *c.RenderContext) image.Image {
 	art.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	art.Ctx.Clear()
 	rainbowColors := []color.RGBA{
@@ -60,24 +64,24 @@ func (art *AnimatedRainbowText) Render() image.Image {
 		{148, 0, 211, 255}, // Violet
 	}
 
-	// Get the size of the text
-	_, h := art.Ctx.MeasureString(art.Text)
-	startX := 0.0
-
+	// give each character the next color in the rainbow
+	var line textLine
 	for _, char := range art.Text {
 		currentColor := rainbowColors[art.colorIndex]
-		art.Ctx.SetColor(currentColor)
-		charStr := string(char)
-		art.Ctx.DrawString(charStr, startX, h) // Draw each character
-
-		// Update starting x-coordinate for next character
-		cw, _ := art.Ctx.MeasureString(charStr)
-		startX += cw
+		line = append(line, textRun{string(char), &textStyle{face: art.face, color: currentColor}})
 
 		// Update color index for the next character
 		art.colorIndex = (art.colorIndex + 1) % len(rainbowColors)
 	}
 
+	// draw on the font's baseline, then apply any effects over the background
+	elapsed := art.elapsed(ctx.Now)
+	layer := art.clearLayer(image.Rect(0, 0, art.ComputedSizeX, art.ComputedSizeY))
+	line.drawGlyphs(layer, art.margin(), art.face.Metrics().Ascent.Ceil()+art.marginY(), 0, func(i int) (int, int, bool) {
+		return art.placeGlyph(elapsed, i)
+	})
+	art.compose(art.Ctx.Image().(*image.RGBA), layer, elapsed, ctx.Rand)
+
 	return art.Ctx.Image()
 }
 
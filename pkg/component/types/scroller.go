@@ -2,31 +2,195 @@ package types
 
 import (
 	"encoding/xml"
-	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
-	"github.com/fogleman/gg"
 	"image"
 	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/fogleman/gg"
+)
+
+const (
+	scrollerFrameRate  = 30  // render rate in milliseconds
+	scrollerLegacyRate = 400 // render rate scroll-x and scroll-y were tuned for
 )
 
+// Scroller moves its template across the box. In loop mode (the default) the
+// content is repeated so the box is never empty; in bounce mode it travels to
+// the far edge and back. Speeds are in pixels per second, negative moving
+// left or up, so motion is the same whatever the render rate
 type Scroller struct {
 	c.BaseComponent
 
 	XMLName xml.Name    `xml:"scroller"`
-	ScrollX int         `xml:"scroll-x,attr"`
-	ScrollY int         `xml:"scroll-y,attr"`
+	ScrollX int         `xml:"scroll-x,attr"` // deprecated, pixels per 400ms
+	ScrollY int         `xml:"scroll-y,attr"` // deprecated, pixels per 400ms
+	SpeedX  float64     `xml:"speed-x,attr"`
+	SpeedY  float64     `xml:"speed-y,attr"`
+	Mode    string      `xml:"mode,attr"`  // loop or bounce
+	Gap     int         `xml:"gap,attr"`   // space between repeats when looping
+	Pause   string      `xml:"pause,attr"` // how long to rest on each item, e.g. "2s"
 	Slot    *c.Template `xml:"template"`
+
+	pause time.Duration
+	start time.Time
+}
+
+// scrollPath a distance travelled from 0 to length, resting at each stop
+type scrollPath struct {
+	length float64
+	stops  []float64
+}
+
+// duration returns how long it takes to travel the whole path
+func (p scrollPath) duration(speed float64, pause time.Duration) time.Duration {
+	return time.Duration(p.length/speed) + time.Duration(len(p.stops))*pause
+}
+
+// at returns the position along the path after phase. speed is in pixels
+// per nanosecond
+func (p scrollPath) at(phase time.Duration, speed float64, pause time.Duration) float64 {
+	pos := 0.0
+	for _, stop := range p.stops {
+		move := time.Duration((stop - pos) / speed)
+		if phase < move {
+			return pos + float64(phase)*speed
+		}
+		phase -= move
+		pos = stop
+		if phase < pause {
+			return pos
+		}
+		phase -= pause
+	}
+	return math.Min(pos+float64(phase)*speed, p.length)
 }
 
 func (s *Scroller) Init() {
-	s.Rr = 400 // render this one a bit faster than most
+	s.Rr = scrollerFrameRate
 	s.BaseComponent.Init()
 	s.Slot.SetParentSize(s.ComputedSizeX, s.ComputedSizeY)
 	s.Slot.Init()
+
+	// keep older templates moving at the speed they were written for
+	if s.SpeedX == 0 && s.ScrollX != 0 {
+		s.SpeedX = float64(s.ScrollX) * 1000 / scrollerLegacyRate
+	}
+	if s.SpeedY == 0 && s.ScrollY != 0 {
+		s.SpeedY = float64(s.ScrollY) * 1000 / scrollerLegacyRate
+	}
+	if s.Mode == "" {
+		s.Mode = "loop"
+	}
+	// repeats can't overlap, they'd never get anywhere
+	s.Gap = int(math.Max(0, float64(s.Gap)))
+	s.pause = c.ParseDuration(s.Pause, 0)
 }
 
-func (s *Scroller) Render() image.Image {
+// Reconcile keeps scrolling from where the scroller it replaces was, so
+// refreshed content doesn't jump back to the start
+func (s *Scroller) Reconcile(prev c.Component) {
+	p, ok := prev.(*Scroller)
+	if !ok {
+		prev.Stop()
+		return
+	}
+	s.Slot.Reconcile(p.Slot)
+	s.start = p.start
+	p.BaseComponent.Stop()
+}
+
+// contentSize returns the size of what the slot actually drew, which is
+// often much smaller than the slot itself
+func (s *Scroller) contentSize(im image.Image) image.Point {
+	bounds := s.Slot.ChildBounds()
+	if len(bounds) == 0 {
+		return im.Bounds().Size()
+	}
+	var size image.Point
+	for _, b := range bounds {
+		size.X = int(math.Max(float64(size.X), float64(b.Max.X)))
+		size.Y = int(math.Max(float64(size.Y), float64(b.Max.Y)))
+	}
+	return size
+}
+
+// offsets returns where to draw each copy of the content along one axis.
+// items are the start of each child along the axis, used for pausing
+func (s *Scroller) offsets(elapsed time.Duration, speed float64, content int, view int, items []int) []int {
+	if speed == 0 || content <= 0 {
+		return []int{0}
+	}
+	perNs := math.Abs(speed) / float64(time.Second)
+
+	if s.Mode == "bounce" {
+		travel := float64(content - view)
+		if travel <= 0 {
+			return []int{0}
+		}
+		out := scrollPath{length: travel, stops: []float64{0}}
+		back := scrollPath{length: travel, stops: []float64{0}}
+		for _, item := range items {
+			if item > 0 && float64(item) < travel {
+				out.stops = append(out.stops, float64(item))
+				back.stops = append(back.stops, travel-float64(item))
+			}
+		}
+		sort.Float64s(out.stops)
+		sort.Float64s(back.stops)
+		there := out.duration(perNs, s.pause)
+		round := there + back.duration(perNs, s.pause)
+		if round <= 0 {
+			return []int{0}
+		}
+		phase := elapsed % round
+		if phase < there {
+			return []int{-int(out.at(phase, perNs, s.pause))}
+		}
+		return []int{-int(travel - back.at(phase-there, perNs, s.pause))}
+	}
+
+	// loop, resting whenever an item lines up with the leading edge
+	period := content + s.Gap
+	if period <= 0 {
+		return []int{0}
+	}
+	path := scrollPath{length: float64(period), stops: []float64{0}}
+	for _, item := range items {
+		stop := item
+		if speed > 0 {
+			stop = period - item
+		}
+		if stop > 0 && stop < period {
+			path.stops = append(path.stops, float64(stop))
+		}
+	}
+	sort.Float64s(path.stops)
+	duration := path.duration(perNs, s.pause)
+	if duration <= 0 {
+		return []int{0}
+	}
+	pos := int(path.at(elapsed%duration, perNs, s.pause))
+
+	first := pos % period
+	if speed < 0 {
+		first = -first
+	}
+	if first > 0 {
+		first -= period
+	}
+	var out []int
+	for o := first; o < view; o += period {
+		out = append(out, o)
+	}
+	return out
+}
+
+func (s *Scroller) Render(ctx *c.RenderContext) image.Image {
 	// render the slot
-	im := s.Slot.Render()
+	im := s.Slot.Render(ctx)
 
 	if s.Ctx == nil {
 		s.Ctx = gg.NewContext(s.ComputedSizeX, s.ComputedSizeY)
@@ -35,21 +199,43 @@ func (s *Scroller) Render() image.Image {
 	s.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
 	s.Ctx.Clear()
 
-	s.Ctx.DrawImage(im, s.PosX, s.PosY)
+	size := s.contentSize(im)
+	var itemsX, itemsY []int
+	if s.pause > 0 {
+		for _, b := range s.Slot.ChildBounds() {
+			itemsX = append(itemsX, b.Min.X)
+			itemsY = append(itemsY, b.Min.Y)
+		}
+	}
 
-	s.PosX = s.PosX + s.ScrollX
-	s.PosY = s.PosY + s.ScrollY
+	// only draw the content itself so repeats butt up against each other
+	content := im
+	if sub, ok := im.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		content = sub.SubImage(image.Rectangle{Max: size})
+	}
 
-	// wrap around
-	if s.ScrollX < 0 {
-		if s.PosX+s.Ctx.Width() < 0 {
-			s.PosX = 0
+	if s.start.IsZero() {
+		s.start = ctx.Now
+	}
+	elapsed := ctx.Now.Sub(s.start)
+	xs := s.offsets(elapsed, s.SpeedX, size.X, s.ComputedSizeX, itemsX)
+	ys := s.offsets(elapsed, s.SpeedY, size.Y, s.ComputedSizeY, itemsY)
+	for _, y := range ys {
+		for _, x := range xs {
+			s.Ctx.DrawImage(content, x, y)
 		}
 	}
 
 	return s.Ctx.Image()
 }
 
+func (s *Scroller) Stop() {
+	s.Slot.Stop()
+	s.BaseComponent.Stop()
+}
+
 func init() {
 	c.RegisterComponent("scroller", func() c.Component { return &Scroller{} })
 }
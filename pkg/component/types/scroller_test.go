@@ -0,0 +1,20 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScrollerOffsets(t *testing.T) {
+	s := &Scroller{Gap: 4}
+	assert.Equal(t, []int{0, 14}, s.offsets(0, -10, 10, 20, nil))
+	assert.Equal(t, []int{-5, 9}, s.offsets(500*time.Millisecond, -10, 10, 20, nil))
+
+	// a gap that would overlap the repeats doesn't loop forever
+	s = &Scroller{Gap: -10}
+	assert.Equal(t, []int{0}, s.offsets(time.Second, -10, 10, 20, nil))
+	s = &Scroller{Gap: -20}
+	assert.Equal(t, []int{0}, s.offsets(time.Second, -10, 10, 20, nil))
+}
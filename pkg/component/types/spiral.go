@@ -21,12 +21,13 @@ func (sg *SpiralGallery) Init() {
 	sg.BaseComponent.Init()
 
 	// Initialize all slots
-	for _, slot := range sg.Slots {
-		slot.Init()
+	for i := range sg.Slots {
+		sg.Slots[i].SetParentSize(sg.ComputedSizeX, sg.ComputedSizeY)
+		sg.Slots[i].Init()
 	}
 }
 
-func (sg *SpiralGallery) Render() image.Image {
+func (sg *SpiralGallery) Render(ctx *c.RenderContext) image.Image {
 	numSlots := len(sg.Slots)
 
 	if numSlots == 0 {
@@ -37,7 +38,8 @@ func (sg *SpiralGallery) Render() image.Image {
 	angleStep := 360.0 / float64(numSlots)
 
 	// Render the slots in a spiral manner
-	for i, slot := range sg.Slots {
+	for i := range sg.Slots {
+		slot := &sg.Slots[i]
 		// Determine the angle for this slot
 		currentAngle := sg.Angle + angleStep*float64(i)
 
@@ -47,7 +49,7 @@ func (sg *SpiralGallery) Render() image.Image {
 		x := r*math.Cos(gg.Radians(currentAngle)) + float64(sg.Width()/2)
 		y := r*math.Sin(gg.Radians(currentAngle)) + float64(sg.Height()/2)
 
-		img := slot.Render()
+		img := slot.Render(ctx)
 		sg.Ctx.DrawImageAnchored(img, int(x), int(y), 0.5, 0.5) // Anchored at center
 	}
 
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+)
+
+// Sprite plays an animation laid out as a grid of frames on a sprite
+// sheet, or as a directory of numbered images. Frames are scaled with
+// nearest-neighbor by default so pixel art stays sharp
+type Sprite struct {
+	c.BaseComponent
+
+	XMLName  xml.Name `xml:"sprite"`
+	Src      string   `xml:"src,attr"`     // sprite sheet, or a directory of numbered images
+	FrameW   int      `xml:"frame-w,attr"` // defaults to the sheet's height, for a single row of square frames
+	FrameH   int      `xml:"frame-h,attr"`
+	Frames   int      `xml:"frames,attr"` // how many frames to play, defaults to all of them
+	FPS      float64  `xml:"fps,attr"`
+	Play     string   `xml:"play,attr"`    // loop (default), once, ping-pong or static
+	Fit      string   `xml:"fit,attr"`     // contain (default), cover, stretch or none
+	Scaling  string   `xml:"scaling,attr"` // nearest (default) or smooth
+	frames   []image.Image
+	playback playback
+}
+
+// resolveImagePath looks for relative paths in the configured image
+// directory when they don't exist relative to where we're running
+func resolveImagePath(src string) string {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") || filepath.IsAbs(src) {
+		return src
+	}
+	if _, err := os.Stat(src); err != nil && util.Config.ImageDir != "" {
+		return filepath.Join(util.Config.ImageDir, src)
+	}
+	return src
+}
+
+var frameNumber = regexp.MustCompile(`\d+`)
+
+// loadSequence decodes every image in dir, ordered by the last number in
+// each file name so frame10 comes after frame9
+func loadSequence(dir string) []image.Image {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	type numbered struct {
+		path string
+		n    int
+	}
+	var files []numbered
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".png" && ext != ".gif" && ext != ".jpg" && ext != ".jpeg") {
+			continue
+		}
+		n := -1
+		if nums := frameNumber.FindAllString(entry.Name(), -1); len(nums) > 0 {
+			n, _ = strconv.Atoi(nums[len(nums)-1])
+		}
+		files = append(files, numbered{filepath.Join(dir, entry.Name()), n})
+	}
+	sort.SliceStable(files, func(a, b int) bool {
+		if files[a].n != files[b].n {
+			return files[a].n < files[b].n
+		}
+		return files[a].path < files[b].path
+	})
+
+	var frames []image.Image
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			log.Fatalf("Failed to decode %s: %v", f.path, err)
+		}
+		frames = append(frames, img)
+	}
+	return frames
+}
+
+// sliceSheet cuts a sprite sheet into frames, left to right then top to bottom
+func (s *Sprite) sliceSheet(sheet image.Image) []image.Image {
+	b := sheet.Bounds()
+	if s.FrameH <= 0 {
+		s.FrameH = b.Dy()
+	}
+	if s.FrameW <= 0 {
+		s.FrameW = s.FrameH
+	}
+
+	var frames []image.Image
+	for y := b.Min.Y; y+s.FrameH <= b.Max.Y; y += s.FrameH {
+		for x := b.Min.X; x+s.FrameW <= b.Max.X; x += s.FrameW {
+			frame := image.NewRGBA(image.Rect(0, 0, s.FrameW, s.FrameH))
+			draw.Draw(frame, frame.Bounds(), sheet, image.Pt(x, y), draw.Src)
+			frames = append(frames, frame)
+		}
+	}
+	return frames
+}
+
+func (s *Sprite) Init() {
+	s.Rr = -1
+	s.BaseComponent.Init()
+
+	src := resolveImagePath(s.Src)
+	var frames []image.Image
+	if info, err := os.Stat(src); err == nil && info.IsDir() {
+		frames = loadSequence(src)
+	} else {
+		data, _, err := util.FetchFile(src)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sheet, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			log.Fatal(err)
+		}
+		frames = s.sliceSheet(sheet)
+	}
+	if len(frames) == 0 {
+		log.Fatalf("Sprite '%s' has no frames", s.Src)
+	}
+	if s.Frames > 0 && s.Frames < len(frames) {
+		frames = frames[:s.Frames]
+	}
+
+	if s.Fit == "" {
+		s.Fit = "contain"
+	}
+	fit := util.ImageFit{Fit: s.Fit, FocusX: 0.5, FocusY: 0.5, Nearest: s.Scaling != "smooth"}
+	for _, frame := range frames {
+		s.frames = append(s.frames, util.FitImage(frame, s.ComputedSizeX, s.ComputedSizeY, fit))
+	}
+
+	if s.FPS <= 0 {
+		s.FPS = 10
+	}
+	delays := make([]time.Duration, len(s.frames))
+	for ix := range delays {
+		delays[ix] = time.Duration(float64(time.Second) / s.FPS)
+	}
+	if rate := s.playback.init(delays, s.Play, 0); rate > 0 {
+		s.SetRate(rate)
+	}
+}
+
+func (s *Sprite) Render(ctx *c.RenderContext) image.Image {
+	return s.frames[s.playback.frame(ctx.Now)]
+}
+
+func init() {
+	c.RegisterComponent("sprite", func() c.Component { return &Sprite{} })
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"math"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+// Starfield flies through a field of stars, the nearer ones brighter
+// along the palette
+type Starfield struct {
+	c.BaseComponent
+	effect
+
+	XMLName xml.Name `xml:"starfield"`
+	Count   int      `xml:"count,attr"` // how many stars, defaults to one per 16 pixels
+	stars   []star
+	prev    float64
+}
+
+type star struct {
+	x, y, z float64 // x and y from -1 to 1, z from the eye (0) to the back (1)
+}
+
+func (s *Starfield) Init() {
+	s.Rr = effectFrameRate
+	s.BaseComponent.Init()
+	s.initEffect("mono")
+	if s.Count <= 0 {
+		s.Count = s.ComputedSizeX * s.ComputedSizeY / 16
+	}
+}
+
+func (s *Starfield) Render(ctx *c.RenderContext) image.Image {
+	if s.stars == nil {
+		for i := 0; i < s.Count; i++ {
+			s.stars = append(s.stars, star{ctx.Rand.Float64()*2 - 1, ctx.Rand.Float64()*2 - 1, ctx.Rand.Float64()})
+		}
+	}
+
+	t := s.elapsed(ctx.Now)
+	dz := (t - s.prev) * 0.5
+	s.prev = t
+
+	s.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
+	s.Ctx.Clear()
+	dst := s.Ctx.Image().(*image.RGBA)
+	cx, cy := float64(s.ComputedSizeX)/2, float64(s.ComputedSizeY)/2
+	scale := math.Max(cx, cy)
+	for i := range s.stars {
+		st := &s.stars[i]
+		st.z -= dz
+		x := int(cx + st.x/st.z*scale)
+		y := int(cy + st.y/st.z*scale)
+		if st.z <= 0.01 || !(image.Point{x, y}).In(dst.Bounds()) {
+			// flew past, start again at the back
+			*st = star{ctx.Rand.Float64()*2 - 1, ctx.Rand.Float64()*2 - 1, 1}
+			continue
+		}
+		dst.SetRGBA(x, y, s.palette.at(1-st.z))
+	}
+	return dst
+}
+
+func init() {
+	c.RegisterComponent("starfield", func() c.Component { return &Starfield{} })
+}
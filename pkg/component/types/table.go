@@ -0,0 +1,380 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/draw"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/fogleman/gg"
+)
+
+const tableFrameRate = 30 // render rate in milliseconds
+
+// Table lines its cells up in rows and columns, e.g.
+//
+//	<table size-x="128" cols="auto,16,16" align="left,right,right" stripe="#FFFFFF18">
+//		<tr><td><text>Eagles</text></td><td><text>10</text></td><td><text>2</text></td></tr>
+//	</table>
+//
+// Columns are a number of pixels, a percent of the table's width or auto to
+// fit their widest cell. Space left over in a table with a width is shared
+// by the auto columns, and when there isn't enough the widest of them are
+// cut short. Cell content sized in percent fills its column once the
+// column's width is known, so it doesn't widen an auto column.
+// Without a width the table is as wide as its columns
+type Table struct {
+	c.BaseComponent
+
+	XMLName   xml.Name    `xml:"table"`
+	Cols      string      `xml:"cols,attr"`       // column widths, e.g. "auto,25%,12"
+	Align     string      `xml:"align,attr"`      // left, center or right for each column
+	VAlign    string      `xml:"valign,attr"`     // top, center or bottom
+	RowHeight string      `xml:"row-height,attr"` // pixels, a percent of the table's height, or auto
+	GapX      int         `xml:"gap-x,attr"`      // space between columns
+	GapY      int         `xml:"gap-y,attr"`      // space between rows
+	BgColor   string      `xml:"bg-color,attr"`
+	Stripe    string      `xml:"stripe,attr"` // background of every other row, starting with the second
+	Rows      []*tableRow `xml:"tr"`
+
+	widths  []int
+	heights []int
+	bg      color.Color
+	stripe  color.Color
+}
+
+// tableRow a row of cells, its height and background overriding the
+// table's
+type tableRow struct {
+	Height  string       `xml:"height,attr"`
+	BgColor string       `xml:"bg-color,attr"`
+	Cells   []*tableCell `xml:"td"`
+
+	bg color.Color
+}
+
+// tableCell a template laid out in its cell, aligned with align and valign
+// in place of justify, and spanning span columns
+type tableCell struct {
+	*c.Template
+	VAlign string
+	Span   int
+}
+
+func (cell *tableCell) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "valign":
+			cell.VAlign = attr.Value
+		case "span":
+			cell.Span, _ = strconv.Atoi(attr.Value)
+		}
+	}
+	if cell.Span < 1 {
+		cell.Span = 1
+	}
+	cell.Template = &c.Template{}
+	return cell.Template.UnmarshalXML(d, start)
+}
+
+// tableAlign maps left/top, center and right/bottom onto a template's
+// start, center and end
+func tableAlign(value string) string {
+	switch value {
+	case "center", "middle":
+		return "center"
+	case "right", "bottom", "end":
+		return "end"
+	}
+	return "start"
+}
+
+// tableSize parses a column width or row height, returning whether it's
+// auto
+func tableSize(value string, total int) (int, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "auto" {
+		return 0, true
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		if err == nil {
+			return int(float64(total) * percent / 100), false
+		}
+	} else if px, err := strconv.Atoi(value); err == nil {
+		return px, false
+	}
+	log.Printf("Invalid table size '%s', using auto", value)
+	return 0, true
+}
+
+// contentSize how much room the cell's children take up laid out in its
+// direction
+func contentSize(t *c.Template) (int, int) {
+	var w, h int
+	for _, child := range t.Components {
+		switch t.Direction {
+		case "col":
+			w, h = int(math.Max(float64(w), float64(child.Width()))), h+child.Height()
+		case "stack":
+			w, h = int(math.Max(float64(w), float64(child.Width()))), int(math.Max(float64(h), float64(child.Height())))
+		default:
+			w, h = w+child.Width(), int(math.Max(float64(h), float64(child.Height())))
+		}
+	}
+	return w, h
+}
+
+func (t *Table) Init() {
+	t.Rr = tableFrameRate
+	t.BaseComponent.Init()
+
+	cols := strings.Split(t.Cols, ",")
+	aligns := strings.Split(t.Align, ",")
+	columns := 0
+	for _, row := range t.Rows {
+		n := 0
+		for _, cell := range row.Cells {
+			n += cell.Span
+		}
+		columns = int(math.Max(float64(columns), float64(n)))
+	}
+	if t.Cols != "" {
+		columns = int(math.Max(float64(columns), float64(len(cols))))
+	}
+
+	// fixed and percent columns are known up front, auto ones once their
+	// cells are measured
+	widthBase := t.ComputedSizeX
+	if widthBase == 0 {
+		widthBase = t.ParentWidth
+	}
+	heightBase := t.ComputedSizeY
+	if heightBase == 0 {
+		heightBase = t.ParentHeight
+	}
+	t.widths = make([]int, columns)
+	auto := make([]bool, columns)
+	for i := range t.widths {
+		rule := ""
+		if i < len(cols) {
+			rule = cols[i]
+		}
+		t.widths[i], auto[i] = tableSize(rule, widthBase)
+	}
+
+	t.heights = make([]int, len(t.Rows))
+	autoRow := make([]bool, len(t.Rows))
+	for r, row := range t.Rows {
+		rule := row.Height
+		if rule == "" {
+			rule = t.RowHeight
+		}
+		t.heights[r], autoRow[r] = tableSize(rule, heightBase)
+
+		col := 0
+		for _, cell := range row.Cells {
+			colAlign := ""
+			if col < len(aligns) {
+				colAlign = strings.TrimSpace(aligns[col])
+			}
+			if cell.Align == "" {
+				cell.Align = colAlign
+			}
+			if cell.VAlign == "" {
+				cell.VAlign = t.VAlign
+			}
+			if cell.Direction == "col" {
+				cell.Justify, cell.Align = tableAlign(cell.VAlign), tableAlign(cell.Align)
+			} else {
+				cell.Justify, cell.Align = tableAlign(cell.Align), tableAlign(cell.VAlign)
+			}
+			if cell.BgColor == "" {
+				cell.BgColor = "transparent"
+			}
+
+			// size what's known so children can size themselves off it
+			if cell.Span == 1 && col < columns && !auto[col] {
+				cell.SizeX = strconv.Itoa(t.widths[col])
+			}
+			if !autoRow[r] {
+				cell.SizeY = strconv.Itoa(t.heights[r])
+			}
+			cell.SetParentSize(t.widths[int(math.Min(float64(col), float64(columns-1)))], t.heights[r])
+			cell.Init()
+
+			w, h := contentSize(cell.Template)
+			if cell.Span == 1 && auto[col] {
+				t.widths[col] = int(math.Max(float64(t.widths[col]), float64(w)))
+			}
+			if autoRow[r] {
+				t.heights[r] = int(math.Max(float64(t.heights[r]), float64(h)))
+			}
+			col += cell.Span
+		}
+	}
+
+	t.fitColumns(auto)
+	t.layout()
+
+	if t.BgColor != "" {
+		t.bg = c.ParseBgColor(t.BgColor)
+	}
+	if t.Stripe != "" {
+		t.stripe = c.ParseBgColor(t.Stripe)
+	}
+	for _, row := range t.Rows {
+		if row.BgColor != "" {
+			row.bg = c.ParseBgColor(row.BgColor)
+		}
+	}
+}
+
+// fitColumns shares out the table's spare width between the auto columns,
+// or narrows the widest of them until they fit
+func (t *Table) fitColumns(auto []bool) {
+	if t.ComputedSizeX == 0 {
+		return
+	}
+	used, autoWidth, autos := t.GapX*int(math.Max(0, float64(len(t.widths)-1))), 0, 0
+	for i, w := range t.widths {
+		if auto[i] {
+			autoWidth += w
+			autos++
+		} else {
+			used += w
+		}
+	}
+	if autos == 0 {
+		return
+	}
+
+	spare := t.ComputedSizeX - used - autoWidth
+	if spare >= 0 {
+		for i := range t.widths {
+			if auto[i] {
+				// the last auto column picks up the remainder
+				share := spare / autos
+				t.widths[i] += share
+				spare -= share
+				autos--
+			}
+		}
+		return
+	}
+
+	// too wide, cap the auto columns at the widest they can all be so the
+	// narrow ones, often numbers, keep their width and the wide ones, often
+	// names, get cut short
+	var sorted []int
+	for i, w := range t.widths {
+		if auto[i] {
+			sorted = append(sorted, w)
+		}
+	}
+	sort.Ints(sorted)
+	room, limit := int(math.Max(0, float64(t.ComputedSizeX-used))), 0
+	for i, w := range sorted {
+		if left := len(sorted) - i; w*left > room {
+			limit = room / left
+			break
+		}
+		room -= w
+	}
+	for i, w := range t.widths {
+		if auto[i] && w > limit {
+			t.widths[i] = limit
+		}
+	}
+}
+
+// layout sizes each cell to its columns and row, and the table to fit them
+// when it wasn't given a size
+func (t *Table) layout() {
+	width := 0
+	for i, w := range t.widths {
+		width += w
+		if i > 0 {
+			width += t.GapX
+		}
+	}
+	height := 0
+	for r, h := range t.heights {
+		height += h
+		if r > 0 {
+			height += t.GapY
+		}
+	}
+	if t.ComputedSizeX == 0 {
+		t.ComputedSizeX = width
+	}
+	if t.ComputedSizeY == 0 {
+		t.ComputedSizeY = height
+	}
+	t.Ctx = gg.NewContext(t.ComputedSizeX, t.ComputedSizeY)
+
+	for r, row := range t.Rows {
+		col := 0
+		for _, cell := range row.Cells {
+			w := 0
+			for i := col; i < col+cell.Span && i < len(t.widths); i++ {
+				if i > col {
+					w += t.GapX
+				}
+				w += t.widths[i]
+			}
+			if cell.ComputedSizeX != w || cell.ComputedSizeY != t.heights[r] {
+				cell.Resize(w, t.heights[r])
+			}
+			col += cell.Span
+		}
+	}
+}
+
+func (t *Table) Render(ctx *c.RenderContext) image.Image {
+	t.Ctx.SetColor(color.Transparent)
+	t.Ctx.Clear()
+	dst := t.Ctx.Image().(draw.Image)
+	if t.bg != nil {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(t.bg), image.Point{}, draw.Over)
+	}
+
+	y := 0
+	for r, row := range t.Rows {
+		rowBounds := image.Rect(0, y, t.ComputedSizeX, y+t.heights[r])
+		if bg := row.bg; bg != nil {
+			draw.Draw(dst, rowBounds, image.NewUniform(bg), image.Point{}, draw.Over)
+		} else if t.stripe != nil && r%2 == 1 {
+			draw.Draw(dst, rowBounds, image.NewUniform(t.stripe), image.Point{}, draw.Over)
+		}
+
+		x := 0
+		for _, cell := range row.Cells {
+			if cell.ComputedSizeX > 0 && cell.ComputedSizeY > 0 {
+				c.Composite(dst, cell.Render(ctx), image.Pt(x, y), cell.DrawState(ctx.Now))
+			}
+			x += cell.ComputedSizeX + t.GapX
+		}
+		y += t.heights[r] + t.GapY
+	}
+	return t.Ctx.Image()
+}
+
+func (t *Table) Stop() {
+	for _, row := range t.Rows {
+		for _, cell := range row.Cells {
+			cell.Stop()
+		}
+	}
+	t.BaseComponent.Stop()
+}
+
+func init() {
+	c.RegisterComponent("table", func() c.Component { return &Table{} })
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"encoding/xml"
+	"testing"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/component/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTablePercentCell(t *testing.T) {
+	golden.Fonts(t)
+	tmpl := &c.Template{}
+	require.NoError(t, xml.Unmarshal([]byte(`<template size-x="64" size-y="16">
+		<table size-x="64" cols="auto,20" gap-x="2" row-height="8">
+			<tr>
+				<td><text size-x="100%" font="Go" style="Regular" size="7" color="#FFFFFFFF">Eagles</text></td>
+				<td><text font="Go" style="Regular" size="7" color="#FFFFFFFF">10</text></td>
+			</tr>
+		</table>
+	</template>`), tmpl))
+	tmpl.Init()
+	defer tmpl.Stop()
+
+	// the auto column gets what the fixed one leaves, and a percent sized
+	// cell fills it
+	table := tmpl.Components[0].(*Table)
+	assert.Equal(t, []int{42, 20}, table.widths)
+	cell := table.Rows[0].Cells[0]
+	assert.Equal(t, 42, cell.Width())
+	assert.Equal(t, 42, cell.Components[0].Width())
+}
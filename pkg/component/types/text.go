@@ -5,77 +5,307 @@ import (
 	"fmt"
 	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
 	"github.com/6ixisgood/matrix-ticker/pkg/util"
-	"github.com/fogleman/gg"
-	"github.com/golang/freetype"
-	"github.com/golang/freetype/truetype"
 	fontpkg "golang.org/x/image/font"
 	"image"
+	"image/draw"
+	"io"
 	"log"
 	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	marqueeFrameRate    = 30 // render rate in milliseconds while scrolling
+	marqueeDefaultSpeed = 20 // pixels per second
+	marqueeDefaultGap   = 16
 )
 
 type Text struct {
 	c.BaseComponent
+	textEffects
+
+	XMLName    xml.Name  `xml:"text"`
+	Font       string    `xml:"font,attr"`
+	FontStyle  string    `xml:"style,attr"`
+	FontSize   float64   `xml:"size,attr"`
+	Color      util.RGBA `xml:"color,attr"`
+	Wrap       bool      `xml:"wrap,attr"`        // wrap onto multiple lines at size-x
+	LineHeight float64   `xml:"line-height,attr"` // distance between baselines in pixels
+	MaxLines   int       `xml:"max-lines,attr"`   // maximum number of lines to show when wrapping
+	Overflow   string    `xml:"overflow,attr"`    // clip (default), ellipsis or scroll
+	TextAlign  string    `xml:"text-align,attr"`  // left (default), center or right
+	Text       string    `xml:",chardata"`
+	Inner      string    `xml:",innerxml"` // raw content, used to find inline <span> markup
+
+	// marquee settings, used when overflow is "scroll" and the text doesn't fit
+	ScrollMode  string  `xml:"scroll-mode,attr"`  // bounce (default) or loop
+	ScrollSpeed float64 `xml:"scroll-speed,attr"` // pixels per second
+	ScrollPause string  `xml:"scroll-pause,attr"` // how long to rest at each end, e.g. "1s"
+	ScrollGap   int     `xml:"scroll-gap,attr"`   // space between repeats when looping
+
+	img       *image.RGBA
+	base      *textStyle
+	faces     map[string]fontpkg.Face
+	lines     []textLine
+	baselines []int
+
+	strip     *image.RGBA // full width rendering of the text when scrolling
+	scrolling bool
+	pause     time.Duration
+}
 
-	XMLName   xml.Name  `xml:"text"`
+// textSpan the attributes a <span> can override within a text component
+type textSpan struct {
 	Font      string    `xml:"font,attr"`
 	FontStyle string    `xml:"style,attr"`
 	FontSize  float64   `xml:"size,attr"`
 	Color     util.RGBA `xml:"color,attr"`
-	Text      string    `xml:",chardata"`
-
-	img   *image.RGBA
-	ftCtx *freetype.Context
 }
 
 func (t *Text) Init() {
 	t.Rr = -1 // no need to rerender this once created
 	t.BaseComponent.Init()
 
-	t.Ctx = gg.NewContext(0, 0)
-
 	// init the font and style
-	var font = util.LoadFont(fmt.Sprintf("%s-%s", t.Font, t.FontStyle))
-	var face = truetype.NewFace(font, &truetype.Options{Size: t.FontSize})
-	t.Ctx.SetFontFace(face)
-
-	// get the size of the string
-	w, h := t.Ctx.MeasureString(t.Text)
-	w_i := int(math.Ceil(w))
-	h_i := int(math.Ceil(h))
+	t.faces = make(map[string]fontpkg.Face)
+	t.base = &textStyle{
+		face:  t.loadFace(t.Font, t.FontStyle, t.FontSize),
+		color: t.Color.RGBA,
+	}
+
+	runs, err := t.parseRuns()
+	if err != nil {
+		log.Fatalf("Unable to parse text content '%s': %v", t.Inner, err)
+	}
+
+	// break the text into lines
+	truncated := false
+	if t.Wrap && t.ComputedSizeX > 0 && t.Overflow != "scroll" {
+		t.lines = wrapRuns(runs, t.ComputedSizeX)
+	} else {
+		t.lines = []textLine{runs}
+	}
+	if t.MaxLines > 0 && len(t.lines) > t.MaxLines {
+		t.lines = t.lines[:t.MaxLines]
+		truncated = true
+	}
+
+	// handle lines that overflow the width of the box
+	if t.Overflow == "scroll" {
+		t.initMarquee()
+	} else if t.ComputedSizeX > 0 {
+		for i, line := range t.lines {
+			suffix := ""
+			if t.Overflow == "ellipsis" && len(line) > 0 {
+				suffix = ellipsisFor(line[len(line)-1].style.face)
+			}
+			lastTruncated := truncated && i == len(t.lines)-1
+			if lastTruncated || (suffix != "" && line.width() > t.ComputedSizeX) {
+				t.lines[i] = truncateLine(line, t.ComputedSizeX, suffix)
+			}
+		}
+	}
+
+	// place each line's baseline below the one before it
+	t.baselines = t.baselines[:0]
+	height := 0
+	for i, line := range t.lines {
+		ascent, lineHeight := line.metrics(t.base)
+		baseline := height + ascent
+		if t.LineHeight > 0 && i > 0 {
+			baseline = t.baselines[i-1] + int(math.Round(t.LineHeight))
+		}
+		t.baselines = append(t.baselines, baseline)
+		height = baseline - ascent + lineHeight
+	}
+
+	// get the size of the text block, leaving room for the outline and shadow
+	animated := t.initEffects()
+	margin, marginY := t.margin(), t.marginY()
 	if t.ComputedSizeX == 0 {
-		t.ComputedSizeX = w_i
+		for _, line := range t.lines {
+			t.ComputedSizeX = int(math.Max(float64(t.ComputedSizeX), float64(line.width()+2*margin)))
+		}
 	}
 	if t.ComputedSizeY == 0 {
-		t.ComputedSizeY = h_i
+		t.ComputedSizeY = height + 2*marginY
+	}
+
+	// keep re-rendering animated effects
+	if animated && !t.scrolling {
+		t.SetRate(effectFrameRate * time.Millisecond)
 	}
 
-	// resize context
-	t.Ctx = gg.NewContext(t.ComputedSizeX, t.ComputedSizeY)
 	// set up a blank image
 	t.img = image.NewRGBA(image.Rect(0, 0, t.ComputedSizeX, t.ComputedSizeY))
+	if t.scrolling {
+		t.strip = image.NewRGBA(image.Rect(0, 0, t.lines[0].width()+2*margin, t.ComputedSizeY))
+		t.lines[0].draw(t.strip, margin, t.baselines[0]+marginY)
+	}
+}
 
-	// Set up the freetype context
-	t.ftCtx = freetype.NewContext()
-	t.ftCtx.SetDPI(72)
-	t.ftCtx.SetFont(font)
-	t.ftCtx.SetFontSize(t.FontSize)
-	t.ftCtx.SetClip(t.img.Bounds())
-	t.ftCtx.SetDst(t.img)
-	t.ftCtx.SetSrc(image.NewUniform(t.Color.RGBA)) // set the color
-	t.ftCtx.SetHinting(fontpkg.HintingNone)
+// loadFace loads a face, reusing faces already loaded by this component
+func (t *Text) loadFace(font string, style string, size float64) fontpkg.Face {
+	key := fmt.Sprintf("%s/%.2f", util.FontName(font, style), size)
+	if face, ok := t.faces[key]; ok {
+		return face
+	}
+	face := util.LoadFace(util.FontName(font, style), size)
+	t.faces[key] = face
+	return face
 }
 
-func (t *Text) Render() image.Image {
-	// Convert the point to fixed.Point26_6 format for freetype
-	pt := freetype.Pt(0, int(t.FontSize))
+// parseRuns splits the text's content into styled runs. Inline <span>
+// elements override the color, font, style and size of the text they wrap
+// and can be nested
+func (t *Text) parseRuns() (textLine, error) {
+	if !strings.Contains(t.Inner, "<") {
+		return textLine{{t.Text, t.base}}, nil
+	}
 
-	// draw to the image
-	_, err := t.ftCtx.DrawString(t.Text, pt)
-	if err != nil {
-		log.Fatal(err)
+	type frame struct {
+		span  textSpan
+		style *textStyle
+	}
+	root := frame{textSpan{Font: t.Font, FontStyle: t.FontStyle, FontSize: t.FontSize}, t.base}
+	stack := []frame{}
+
+	var runs textLine
+	d := xml.NewDecoder(strings.NewReader("<span>" + t.Inner + "</span>"))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return runs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if tok.Name.Local != "span" {
+				// not text markup, leave it for whoever owns it
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if len(stack) == 0 {
+				stack = append(stack, root)
+				continue
+			}
+
+			// inherit from the enclosing span, overriding what's set
+			parent := stack[len(stack)-1]
+			span := parent.span
+			style := *parent.style
+			for _, attr := range tok.Attr {
+				switch attr.Name.Local {
+				case "font":
+					span.Font = attr.Value
+				case "style":
+					span.FontStyle = attr.Value
+				case "size":
+					span.FontSize, err = strconv.ParseFloat(attr.Value, 64)
+				case "color":
+					err = span.Color.UnmarshalXMLAttr(attr)
+					style.color = span.Color.RGBA
+				}
+				if err != nil {
+					return nil, fmt.Errorf("bad span attribute %s: %v", attr.Name.Local, err)
+				}
+			}
+			style.face = t.loadFace(span.Font, span.FontStyle, span.FontSize)
+			stack = append(stack, frame{span, &style})
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			runs = append(runs, textRun{string(tok), stack[len(stack)-1].style})
+		}
+	}
+}
+
+// initMarquee sets up scrolling when the text is wider than its box. Text
+// that fits is left static
+func (t *Text) initMarquee() {
+	width := t.lines[0].width()
+	if t.ComputedSizeX == 0 || width <= t.ComputedSizeX {
+		return
+	}
+
+	if t.ScrollSpeed <= 0 {
+		t.ScrollSpeed = marqueeDefaultSpeed
+	}
+	if t.ScrollGap <= 0 {
+		t.ScrollGap = marqueeDefaultGap
+	}
+	t.pause = c.ParseDuration(t.ScrollPause, time.Second)
+
+	// re-render regularly while scrolling
+	t.scrolling = true
+	t.SetRate(marqueeFrameRate * time.Millisecond)
+}
+
+// marqueeOffset returns how far the strip is shifted left after elapsed time
+func (t *Text) marqueeOffset(elapsed time.Duration) int {
+	speed := t.ScrollSpeed / float64(time.Second)
+
+	if t.ScrollMode == "loop" {
+		// pause with the start of the text in view, then scroll one full repeat
+		span := float64(t.strip.Bounds().Dx() + t.ScrollGap)
+		// at least a nanosecond, however fast, so the cycle is never 0
+		move := time.Duration(math.Max(1, span/speed))
+		phase := elapsed % (t.pause + move)
+		if phase < t.pause {
+			return 0
+		}
+		return int(float64(phase-t.pause) * speed)
+	}
+
+	// bounce between both ends, resting at each
+	travel := float64(t.strip.Bounds().Dx() - t.ComputedSizeX)
+	move := time.Duration(math.Max(1, travel/speed))
+	phase := elapsed % (2*t.pause + 2*move)
+	switch {
+	case phase < t.pause:
+		return 0
+	case phase < t.pause+move:
+		return int(float64(phase-t.pause) * speed)
+	case phase < 2*t.pause+move:
+		return int(travel)
+	default:
+		return int(travel - float64(phase-2*t.pause-move)*speed)
+	}
+}
+
+func (t *Text) Render(ctx *c.RenderContext) image.Image {
+	elapsed := t.elapsed(ctx.Now)
+	layer := t.clearLayer(t.img.Bounds())
+
+	if t.scrolling {
+		// glyph effects aren't applied to the pre-rendered strip
+		offset := t.marqueeOffset(elapsed)
+		draw.Draw(layer, layer.Bounds(), t.strip, image.Pt(offset, 0), draw.Over)
+		if t.ScrollMode == "loop" {
+			// draw the repeat so the box is never empty
+			next := t.strip.Bounds().Dx() + t.ScrollGap - offset
+			draw.Draw(layer, layer.Bounds().Add(image.Pt(next, 0)), t.strip, image.Point{}, draw.Over)
+		}
+	} else {
+		// draw each line at its baseline
+		index := 0
+		margin, marginY := t.margin(), t.marginY()
+		place := func(i int) (int, int, bool) { return t.placeGlyph(elapsed, i) }
+		for i, line := range t.lines {
+			x := margin + alignOffset(t.TextAlign, line.width(), t.ComputedSizeX-2*margin)
+			index = line.drawGlyphs(layer, x, t.baselines[i]+marginY, index, place)
+		}
 	}
 
+	draw.Draw(t.img, t.img.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	t.compose(t.img, layer, elapsed, ctx.Rand)
 	return t.img
 }
 
@@ -0,0 +1,48 @@
+package types
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/component/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initText initializes a text component with the given markup on its own
+func initText(t *testing.T, markup string) *Text {
+	tmpl := &c.Template{}
+	require.NoError(t, xml.Unmarshal([]byte(`<template size-x="64" size-y="32">`+markup+`</template>`), tmpl))
+	tmpl.Init()
+	t.Cleanup(tmpl.Stop)
+	return tmpl.Components[0].(*Text)
+}
+
+func TestTextMarquee(t *testing.T) {
+	golden.Fonts(t)
+	marquee := func(attrs string) *Text {
+		return initText(t, `<text size-x="32" overflow="scroll" font="Go" style="Regular" size="10" color="#FFFFFFFF" `+attrs+`>Scrolling along</text>`)
+	}
+
+	// the outline reaches past both ends of the text, the strip has room for it
+	outlined := marquee(`outline-color="#000000FF"`)
+	assert.Equal(t, outlined.lines[0].width()+2, outlined.strip.Bounds().Dx())
+
+	// however fast it scrolls, without a pause there's still a cycle to go round
+	for _, mode := range []string{"loop", "bounce"} {
+		fast := marquee(`scroll-mode="` + mode + `" scroll-pause="0s" scroll-speed="1e12"`)
+		assert.NotPanics(t, func() { fast.marqueeOffset(time.Second) }, mode)
+	}
+}
+
+func TestTextWave(t *testing.T) {
+	golden.Fonts(t)
+	plain := initText(t, `<text font="Go" style="Regular" size="10" color="#FFFFFFFF">Wave</text>`)
+	wave := initText(t, `<text font="Go" style="Regular" size="10" color="#FFFFFFFF" effect="wave" effect-amount="3">Wave</text>`)
+
+	// room for the glyphs to move up and down, but not sideways
+	assert.Equal(t, plain.Height()+6, wave.Height())
+	assert.Equal(t, plain.Width(), wave.Width())
+}
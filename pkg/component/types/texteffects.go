@@ -0,0 +1,190 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+)
+
+const effectFrameRate = 30 // render rate in milliseconds for animated effects
+
+// textEffects decorations and animations shared by the text components.
+// Outline and shadow are drawn around whatever the component renders;
+// typewriter and wave move individual glyphs, blink and glitch apply to
+// the whole block
+type textEffects struct {
+	OutlineColor util.RGBA `xml:"outline-color,attr"`
+	ShadowColor  util.RGBA `xml:"shadow-color,attr"`
+	ShadowX      int       `xml:"shadow-x,attr"`
+	ShadowY      int       `xml:"shadow-y,attr"`
+	Effect       string    `xml:"effect,attr"`        // typewriter, wave, blink or glitch
+	EffectSpeed  float64   `xml:"effect-speed,attr"`  // characters, waves, blinks or glitches per second
+	EffectAmount float64   `xml:"effect-amount,attr"` // wave height in pixels, or glitch intensity 0-1
+
+	layer *image.RGBA
+	start time.Time
+}
+
+// initEffects fills in defaults and reports whether the effect animates,
+// in which case the component needs to keep re-rendering
+func (e *textEffects) initEffects() bool {
+	if e.ShadowColor.A > 0 && e.ShadowX == 0 && e.ShadowY == 0 {
+		e.ShadowX, e.ShadowY = 1, 1
+	}
+
+	switch e.Effect {
+	case "typewriter":
+		if e.EffectSpeed <= 0 {
+			e.EffectSpeed = 8
+		}
+	case "wave":
+		if e.EffectSpeed <= 0 {
+			e.EffectSpeed = 1
+		}
+		if e.EffectAmount <= 0 {
+			e.EffectAmount = 2
+		}
+	case "blink":
+		if e.EffectSpeed <= 0 {
+			e.EffectSpeed = 1
+		}
+	case "glitch":
+		if e.EffectSpeed <= 0 {
+			e.EffectSpeed = 2
+		}
+		if e.EffectAmount <= 0 {
+			e.EffectAmount = 0.3
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// margin returns how many pixels the outline and shadow reach past the glyphs
+func (e *textEffects) margin() int {
+	m := 0
+	if e.OutlineColor.A > 0 {
+		m = 1
+	}
+	if e.ShadowColor.A > 0 {
+		m = int(math.Max(float64(m), math.Max(math.Abs(float64(e.ShadowX)), math.Abs(float64(e.ShadowY)))))
+	}
+	return m
+}
+
+// marginY returns how many pixels glyphs reach past the top and bottom of
+// the text, the wave moves them up and down on top of the margin
+func (e *textEffects) marginY() int {
+	m := e.margin()
+	if e.Effect == "wave" {
+		m += int(math.Ceil(e.EffectAmount))
+	}
+	return m
+}
+
+// elapsed returns how long the effect has been running at now, it starts
+// on the first frame
+func (e *textEffects) elapsed(now time.Time) time.Duration {
+	if e.start.IsZero() {
+		e.start = now
+	}
+	return now.Sub(e.start)
+}
+
+// clearLayer returns a blank layer of the given size to draw glyphs onto
+func (e *textEffects) clearLayer(bounds image.Rectangle) *image.RGBA {
+	if e.layer == nil || e.layer.Bounds() != bounds {
+		e.layer = image.NewRGBA(bounds)
+	} else {
+		draw.Draw(e.layer, bounds, image.Transparent, image.Point{}, draw.Src)
+	}
+	return e.layer
+}
+
+// placeGlyph returns how far to move the glyph at index, and whether it
+// should be drawn at all
+func (e *textEffects) placeGlyph(elapsed time.Duration, index int) (int, int, bool) {
+	switch e.Effect {
+	case "typewriter":
+		shown := int(elapsed.Seconds() * e.EffectSpeed)
+		return 0, 0, index < shown
+	case "wave":
+		phase := 2*math.Pi*elapsed.Seconds()*e.EffectSpeed - float64(index)*0.6
+		return 0, int(math.Round(e.EffectAmount * math.Sin(phase))), true
+	}
+	return 0, 0, true
+}
+
+// compose draws layer onto dst with the shadow, outline, blink and glitch
+// effects applied, glitches are drawn from rnd
+func (e *textEffects) compose(dst *image.RGBA, layer *image.RGBA, elapsed time.Duration, rnd *rand.Rand) {
+	bounds := dst.Bounds()
+
+	if e.Effect == "blink" {
+		cycle := elapsed.Seconds() * e.EffectSpeed
+		if cycle-math.Floor(cycle) >= 0.5 {
+			return
+		}
+	}
+
+	if e.Effect == "glitch" {
+		cycle := elapsed.Seconds() * e.EffectSpeed
+		if cycle-math.Floor(cycle) < e.EffectAmount {
+			layer = e.glitch(layer, rnd)
+			// split the color channels while glitching
+			drawSilhouette(dst, layer, color.RGBA{255, 0, 0, 255}, -1, 0)
+			drawSilhouette(dst, layer, color.RGBA{0, 255, 255, 255}, 1, 0)
+		}
+	}
+
+	if e.ShadowColor.A > 0 {
+		drawSilhouette(dst, layer, e.ShadowColor.RGBA, e.ShadowX, e.ShadowY)
+	}
+
+	if e.OutlineColor.A > 0 {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				if dx != 0 || dy != 0 {
+					drawSilhouette(dst, layer, e.OutlineColor.RGBA, dx, dy)
+				}
+			}
+		}
+	}
+
+	draw.Draw(dst, bounds, layer, bounds.Min, draw.Over)
+}
+
+// glitch returns a copy of layer with a few random bands of rows shifted
+// sideways
+func (e *textEffects) glitch(layer *image.RGBA, rnd *rand.Rand) *image.RGBA {
+	bounds := layer.Bounds()
+	if bounds.Empty() {
+		return layer
+	}
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, layer, bounds.Min, draw.Src)
+
+	bands := 1 + rnd.Intn(3)
+	for i := 0; i < bands; i++ {
+		y0 := bounds.Min.Y + rnd.Intn(bounds.Dy())
+		h := 1 + rnd.Intn(int(math.Max(1, float64(bounds.Dy())/4)))
+		shift := rnd.Intn(int(2+e.EffectAmount*8)) - int(1+e.EffectAmount*4)
+		band := image.Rect(bounds.Min.X, y0, bounds.Max.X, y0+h).Intersect(bounds)
+		draw.Draw(out, band, image.Transparent, image.Point{}, draw.Src)
+		draw.Draw(out, band.Add(image.Pt(shift, 0)), layer, band.Min, draw.Over)
+	}
+	return out
+}
+
+// drawSilhouette draws the shape of layer onto dst in a single color,
+// offset by dx, dy
+func drawSilhouette(dst *image.RGBA, layer *image.RGBA, c color.RGBA, dx int, dy int) {
+	r := dst.Bounds().Add(image.Pt(dx, dy))
+	draw.DrawMask(dst, r, image.NewUniform(c), image.Point{}, layer, layer.Bounds().Min, draw.Over)
+}
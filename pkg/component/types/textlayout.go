@@ -0,0 +1,285 @@
+package types
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"unicode"
+
+	fontpkg "golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+const ellipsis = "…"
+
+// textStyle the face and color used to draw a run of text
+type textStyle struct {
+	face  fontpkg.Face
+	color color.RGBA
+}
+
+// textRun a piece of text drawn in a single style
+type textRun struct {
+	text  string
+	style *textStyle
+}
+
+// textLine a single line of laid out text, made up of one or more runs
+type textLine []textRun
+
+// styledRune a rune along with the style it's drawn in, used while wrapping
+type styledRune struct {
+	r     rune
+	style *textStyle
+}
+
+// ellipsisFor returns the ellipsis to use with face, falling back to three
+// dots for fonts that don't have the glyph
+func ellipsisFor(face fontpkg.Face) string {
+	if f, ok := face.(interface{ HasGlyph(rune) bool }); ok && !f.HasGlyph('…') {
+		return "..."
+	}
+	return ellipsis
+}
+
+// measure returns the advance width of s in whole pixels
+func measure(face fontpkg.Face, s string) int {
+	return fontpkg.MeasureString(face, s).Ceil()
+}
+
+// width returns the advance width of the line in whole pixels
+func (l textLine) width() int {
+	w := 0
+	for _, run := range l {
+		w += measure(run.style.face, run.text)
+	}
+	return w
+}
+
+// String returns the plain text of the line
+func (l textLine) String() string {
+	s := ""
+	for _, run := range l {
+		s += run.text
+	}
+	return s
+}
+
+// metrics returns the tallest ascent and line height of any face on the
+// line. Empty lines use the fallback style
+func (l textLine) metrics(fallback *textStyle) (ascent int, height int) {
+	styles := []*textStyle{fallback}
+	if len(l) > 0 {
+		styles = styles[:0]
+		for _, run := range l {
+			styles = append(styles, run.style)
+		}
+	}
+	for _, style := range styles {
+		m := style.face.Metrics()
+		if a := m.Ascent.Ceil(); a > ascent {
+			ascent = a
+		}
+		if h := m.Height.Ceil(); h > height {
+			height = h
+		}
+	}
+	return ascent, height
+}
+
+// draw renders the line onto dst with its baseline origin at x, y
+func (l textLine) draw(dst *image.RGBA, x int, y int) {
+	l.drawGlyphs(dst, x, y, 0, func(int) (int, int, bool) { return 0, 0, true })
+}
+
+// drawGlyphs renders the line one glyph at a time, letting place move or
+// hide each glyph. index is the position of the line's first glyph within
+// the whole text, and the index after the line's last glyph is returned
+func (l textLine) drawGlyphs(dst *image.RGBA, x int, y int, index int, place func(index int) (int, int, bool)) int {
+	dot := fixed.P(x, y)
+	for _, run := range l {
+		face := run.style.face
+		src := image.NewUniform(run.style.color)
+		prev := rune(-1)
+		for _, r := range run.text {
+			if prev >= 0 {
+				dot.X += face.Kern(prev, r)
+			}
+			if dx, dy, visible := place(index); visible {
+				dr, mask, maskp, _, ok := face.Glyph(dot.Add(fixed.P(dx, dy)), r)
+				if ok {
+					draw.DrawMask(dst, dr, src, image.Point{}, mask, maskp, draw.Over)
+				}
+			}
+			advance, _ := face.GlyphAdvance(r)
+			dot.X += advance
+			prev = r
+			index++
+		}
+	}
+	return index
+}
+
+// styleRunes returns the runes of s, each in the given style
+func styleRunes(s string, style *textStyle) []styledRune {
+	var rs []styledRune
+	for _, r := range s {
+		rs = append(rs, styledRune{r, style})
+	}
+	return rs
+}
+
+// flatten breaks runs into individual styled runes
+func flatten(runs []textRun) []styledRune {
+	var rs []styledRune
+	for _, run := range runs {
+		rs = append(rs, styleRunes(run.text, run.style)...)
+	}
+	return rs
+}
+
+// group joins consecutive runes that share a style back into runs
+func group(rs []styledRune) textLine {
+	var line textLine
+	for _, sr := range rs {
+		if n := len(line); n > 0 && line[n-1].style == sr.style {
+			line[n-1].text += string(sr.r)
+			continue
+		}
+		line = append(line, textRun{string(sr.r), sr.style})
+	}
+	return line
+}
+
+// wrapRuns breaks runs into lines no wider than width. Lines are split on
+// whitespace; a single word wider than the box is broken between runes.
+// Blank lines between paragraphs are kept, ones before the first or after
+// the last are left out so the markup can be indented
+func wrapRuns(runs []textRun, width int) []textLine {
+	paragraphs := splitParagraphs(flatten(runs))
+	for len(paragraphs) > 0 && len(paragraphs[0]) == 0 {
+		paragraphs = paragraphs[1:]
+	}
+	for len(paragraphs) > 0 && len(paragraphs[len(paragraphs)-1]) == 0 {
+		paragraphs = paragraphs[:len(paragraphs)-1]
+	}
+
+	var lines []textLine
+	for _, words := range paragraphs {
+		if len(words) == 0 {
+			lines = append(lines, nil)
+			continue
+		}
+
+		var line []styledRune
+		for _, word := range words {
+			var candidate []styledRune
+			if len(line) > 0 {
+				// join words with a space in the style of the preceding word
+				candidate = append(candidate, line...)
+				candidate = append(candidate, styledRune{' ', line[len(line)-1].style})
+			}
+			candidate = append(candidate, word...)
+			if group(candidate).width() <= width {
+				line = candidate
+				continue
+			}
+
+			// the word doesn't fit on the current line, start a new one
+			if len(line) > 0 {
+				lines = append(lines, group(line))
+			}
+			line = word
+
+			// break up words that are too long on their own
+			for group(line).width() > width {
+				n := fitRunes(line, width)
+				lines = append(lines, group(line[:n]))
+				line = line[n:]
+			}
+		}
+		lines = append(lines, group(line))
+	}
+	return lines
+}
+
+// splitParagraphs splits rs into the words of each line, a blank line
+// having none
+func splitParagraphs(rs []styledRune) [][][]styledRune {
+	var paragraphs [][][]styledRune
+	start := 0
+	for i, sr := range rs {
+		if sr.r == '\n' {
+			paragraphs = append(paragraphs, splitRunes(rs[start:i], unicode.IsSpace))
+			start = i + 1
+		}
+	}
+	return append(paragraphs, splitRunes(rs[start:], unicode.IsSpace))
+}
+
+// splitRunes splits rs around runes matching sep, dropping empty pieces
+func splitRunes(rs []styledRune, sep func(rune) bool) [][]styledRune {
+	var pieces [][]styledRune
+	start := -1
+	for i, sr := range rs {
+		if sep(sr.r) {
+			if start >= 0 {
+				pieces = append(pieces, rs[start:i])
+			}
+			start = -1
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		pieces = append(pieces, rs[start:])
+	}
+	return pieces
+}
+
+// fitRunes returns how many runes from the start of rs fit within width.
+// At least one rune is always kept so wrapping makes progress
+func fitRunes(rs []styledRune, width int) int {
+	n := 1
+	for n < len(rs) && group(rs[:n+1]).width() <= width {
+		n++
+	}
+	return n
+}
+
+// truncateLine drops runes from the end of line until it and the given
+// suffix fit within width, then appends the suffix in the style of the
+// last remaining rune
+func truncateLine(line textLine, width int, suffix string) textLine {
+	rs := flatten(line)
+	if len(rs) == 0 {
+		return line
+	}
+
+	withSuffix := func(rs []styledRune) textLine {
+		style := line[len(line)-1].style
+		if len(rs) > 0 {
+			style = rs[len(rs)-1].style
+		}
+		return group(append(rs[:len(rs):len(rs)], styleRunes(suffix, style)...))
+	}
+
+	for len(rs) > 0 && withSuffix(rs).width() > width {
+		rs = rs[:len(rs)-1]
+		for len(rs) > 0 && rs[len(rs)-1].r == ' ' {
+			rs = rs[:len(rs)-1]
+		}
+	}
+	return withSuffix(rs)
+}
+
+// alignOffset returns the x offset of a line within a box of the given width
+func alignOffset(align string, lineWidth int, boxWidth int) int {
+	switch align {
+	case "center":
+		return (boxWidth - lineWidth) / 2
+	case "right", "end":
+		return boxWidth - lineWidth
+	}
+	return 0
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/basicfont"
+)
+
+func TestWrapRuns(t *testing.T) {
+	style := &textStyle{face: basicfont.Face7x13}
+
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"wrap", "one two three", 50, []string{"one two", "three"}},
+		{"long word", "abcdefghij", 35, []string{"abcde", "fghij"}},
+		{"newline", "one\ntwo", 100, []string{"one", "two"}},
+		{"blank line", "one\n\ntwo", 100, []string{"one", "", "two"}},
+		{"blank lines", "one\n\n\ntwo", 100, []string{"one", "", "", "two"}},
+		{"whitespace line", "one\n   \ntwo", 100, []string{"one", "", "two"}},
+		{"indented", "\n  one\n\n  two\n", 100, []string{"one", "", "two"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range wrapRuns([]textRun{{tt.text, style}}, tt.width) {
+				got = append(got, line.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package types
+
+import (
+	"encoding/xml"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"time"
+
+	c "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
+)
+
+// Visualizer draws audio as spectrum bars, a waveform or VU meters. Audio
+// comes from a WAV or raw PCM file (played on a loop), stdin ("-") or a
+// named pipe such as an MPD fifo or `arecord -t raw` piped in
+type Visualizer struct {
+	c.BaseComponent
+
+	XMLName    xml.Name `xml:"visualizer"`
+	Src        string   `xml:"src,attr"`
+	Mode       string   `xml:"mode,attr"`        // bars (default), wave or vu
+	Encoding   string   `xml:"encoding,attr"`    // of raw PCM, s16le (default), s24le, s32le, u8 or f32le
+	SampleRate int      `xml:"sample-rate,attr"` // of raw PCM, defaults to 44100
+	Channels   int      `xml:"channels,attr"`    // of raw PCM, defaults to 2
+	Bands      int      `xml:"bands,attr"`       // bars to split the spectrum into, defaults to one per 4 pixels
+	Gap        *int     `xml:"gap,attr"`         // pixels between bars, defaults to 1
+	MinHz      float64  `xml:"min-hz,attr"`      // lowest frequency shown, defaults to 40
+	MaxHz      float64  `xml:"max-hz,attr"`      // highest frequency shown, defaults to 16000
+	Floor      float64  `xml:"floor,attr"`       // dB shown as empty, defaults to -60
+	Gain       float64  `xml:"gain,attr"`        // multiplies the input, defaults to 1
+	Fall       float64  `xml:"fall,attr"`        // how fast levels drop, in full heights a second, defaults to 2
+	Peaks      *bool    `xml:"peaks,attr"`       // mark recent peaks on bars and meters, defaults to true
+	Palette    string   `xml:"palette,attr"`     // palette name or comma separated colors, low to high
+	source     util.AudioSource
+	palette    *palette
+	levels     []float64
+	peaks      []float64
+	start      time.Time
+	last       time.Time
+}
+
+func (v *Visualizer) Init() {
+	v.Rr = effectFrameRate
+	v.BaseComponent.Init()
+	if v.Mode == "" {
+		v.Mode = "bars"
+	}
+	if v.Bands <= 0 {
+		v.Bands = int(math.Max(1, float64(v.ComputedSizeX/4)))
+	}
+	if v.Gap == nil {
+		gap := 1
+		v.Gap = &gap
+	}
+	if v.MinHz <= 0 {
+		v.MinHz = 40
+	}
+	if v.MaxHz <= 0 {
+		v.MaxHz = 16000
+	}
+	if v.Floor >= 0 {
+		v.Floor = -60
+	}
+	if v.Gain <= 0 {
+		v.Gain = 1
+	}
+	if v.Fall <= 0 {
+		v.Fall = 2
+	}
+	if v.Peaks == nil {
+		peaks := true
+		v.Peaks = &peaks
+	}
+	v.palette = parsePalette(v.Palette, "meter")
+
+	source, err := util.OpenAudio(v.Src, util.PCMFormat{
+		Encoding:   v.Encoding,
+		SampleRate: v.SampleRate,
+		Channels:   v.Channels,
+	})
+	if err != nil {
+		// leave it silent rather than take everything else down, live
+		// inputs come and go
+		log.Printf("Error opening audio '%s': %v", v.Src, err)
+		return
+	}
+	v.source = source
+}
+
+// window how many samples to analyse at a time, a power of two for the
+// FFT covering about 1/40s
+func (v *Visualizer) window() int {
+	n := 64
+	for n < v.source.SampleRate()/40 {
+		n *= 2
+	}
+	return n
+}
+
+// settle moves the shown levels towards target, jumping up straight away
+// and falling back at the fall rate, with peaks falling a quarter as fast
+func (v *Visualizer) settle(target []float64, dt float64) {
+	if len(v.levels) != len(target) {
+		v.levels = make([]float64, len(target))
+		v.peaks = make([]float64, len(target))
+	}
+	for i, t := range target {
+		v.levels[i] = math.Max(t, v.levels[i]-v.Fall*dt)
+		v.peaks[i] = math.Max(v.levels[i], v.peaks[i]-v.Fall/4*dt)
+	}
+}
+
+func (v *Visualizer) Render(ctx *c.RenderContext) image.Image {
+	if v.start.IsZero() {
+		v.start, v.last = ctx.Now, ctx.Now
+	}
+	dt := ctx.Now.Sub(v.last).Seconds()
+	v.last = ctx.Now
+
+	v.Ctx.SetColor(color.RGBA{0, 0, 0, 255})
+	v.Ctx.Clear()
+	dst := v.Ctx.Image().(*image.RGBA)
+	if v.source == nil {
+		return dst
+	}
+
+	n := v.window()
+	channels := v.source.Samples(ctx.Now.Sub(v.start), n)
+	for _, samples := range channels {
+		for i := range samples {
+			samples[i] *= v.Gain
+		}
+	}
+
+	switch v.Mode {
+	case "wave":
+		v.drawWave(dst, mix(channels))
+	case "vu":
+		levels := make([]float64, len(channels))
+		for ch, samples := range channels {
+			var sum float64
+			for _, s := range samples {
+				sum += s * s
+			}
+			// scaled so a full scale sine reads 0 dB
+			levels[ch] = util.Decibels(math.Sqrt(sum/float64(len(samples)))*math.Sqrt2, v.Floor)
+		}
+		v.settle(levels, dt)
+		v.drawMeters(dst)
+	default:
+		if v.Mode != "bars" {
+			log.Printf("Invalid visualizer mode '%s', using bars", v.Mode)
+			v.Mode = "bars"
+		}
+		maxHz := math.Min(v.MaxHz, float64(v.source.SampleRate())/2)
+		bands := util.SpectrumBands(util.Spectrum(mix(channels)), v.source.SampleRate(), v.Bands, v.MinHz, maxHz)
+		for i, b := range bands {
+			bands[i] = util.Decibels(b, v.Floor)
+		}
+		v.settle(bands, dt)
+		v.drawBars(dst)
+	}
+	return dst
+}
+
+// mix averages the channels down to mono
+func mix(channels [][]float64) []float64 {
+	if len(channels) == 0 {
+		return nil
+	}
+	out := make([]float64, len(channels[0]))
+	for _, samples := range channels {
+		for i, s := range samples {
+			out[i] += s / float64(len(channels))
+		}
+	}
+	return out
+}
+
+// drawBars draws a bar up from the bottom for each band, colored along the
+// palette by height, with a dot for its recent peak
+func (v *Visualizer) drawBars(dst *image.RGBA) {
+	w, h := v.ComputedSizeX, v.ComputedSizeY
+	gap := *v.Gap
+	width := int(math.Max(1, float64((w-gap*(len(v.levels)-1))/len(v.levels))))
+	// center the bars in whatever's left over
+	left := (w - width*len(v.levels) - gap*(len(v.levels)-1)) / 2
+	for i, level := range v.levels {
+		x0 := left + i*(width+gap)
+		top := h - int(math.Round(level*float64(h)))
+		for y := top; y < h; y++ {
+			col := v.palette.at(float64(h-1-y) / float64(h-1))
+			for x := x0; x < x0+width; x++ {
+				dst.SetRGBA(x, y, col)
+			}
+		}
+		if *v.Peaks && v.peaks[i] > 0 {
+			y := int(math.Min(float64(h-1), float64(h)-math.Round(v.peaks[i]*float64(h))))
+			for x := x0; x < x0+width; x++ {
+				dst.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+}
+
+// drawMeters draws a meter across for each channel, colored along the
+// palette from left to right, with a line at its recent peak
+func (v *Visualizer) drawMeters(dst *image.RGBA) {
+	w, h := v.ComputedSizeX, v.ComputedSizeY
+	gap := *v.Gap
+	height := int(math.Max(1, float64((h-gap*(len(v.levels)-1))/len(v.levels))))
+	top := (h - height*len(v.levels) - gap*(len(v.levels)-1)) / 2
+	for i, level := range v.levels {
+		y0 := top + i*(height+gap)
+		right := int(math.Round(level * float64(w)))
+		for x := 0; x < right; x++ {
+			col := v.palette.at(float64(x) / float64(w-1))
+			for y := y0; y < y0+height; y++ {
+				dst.SetRGBA(x, y, col)
+			}
+		}
+		if *v.Peaks && v.peaks[i] > 0 {
+			x := int(math.Min(float64(w-1), math.Round(v.peaks[i]*float64(w))))
+			for y := y0; y < y0+height; y++ {
+				dst.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+			}
+		}
+	}
+}
+
+// drawWave draws the samples as an oscilloscope trace across the middle,
+// louder parts further along the palette
+func (v *Visualizer) drawWave(dst *image.RGBA, samples []float64) {
+	w, h := v.ComputedSizeX, v.ComputedSizeY
+	mid := float64(h-1) / 2
+	prev := -1
+	for x := 0; x < w; x++ {
+		s := math.Max(-1, math.Min(1, samples[x*len(samples)/w]))
+		y := int(math.Round(mid - s*mid))
+		// join up with the last column so steep parts don't break up
+		from, to := y, y
+		if prev >= 0 {
+			from, to = int(math.Min(float64(y), float64(prev))), int(math.Max(float64(y), float64(prev)))
+		}
+		col := v.palette.at(math.Abs(s))
+		for yy := from; yy <= to; yy++ {
+			dst.SetRGBA(x, yy, col)
+		}
+		prev = y
+	}
+}
+
+func init() {
+	c.RegisterComponent("visualizer", func() c.Component { return &Visualizer{} })
+}
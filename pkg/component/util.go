@@ -1,12 +1,11 @@
 package component
 
 import (
-	"encoding/xml"
 	"fmt"
+	"github.com/6ixisgood/matrix-ticker/pkg/util"
 	"github.com/golang/freetype/truetype"
 	"github.com/nfnt/resize"
 	"image"
-	"image/color"
 	"io/ioutil"
 	"log"
 	"os"
@@ -19,19 +18,7 @@ var (
 )
 
 // RGBA Struct wraps color.RGBA for unmarshalling from XML
-type RGBA struct {
-	color.RGBA
-}
-
-func (c *RGBA) UnmarshalXMLAttr(attr xml.Attr) error {
-	var r, g, b, a uint8
-	_, err := fmt.Sscanf(attr.Value, "#%02x%02x%02x%02x", &r, &g, &b, &a)
-	if err != nil {
-		return err
-	}
-	c.RGBA = color.RGBA{r, g, b, a}
-	return nil
-}
+type RGBA = util.RGBA
 
 func loadFont(fontName string) *truetype.Font {
 	// Read font file from disk
@@ -28,6 +28,8 @@ type ApplicationConfig struct {
 		FontStyle  string `yaml:"font_style"`
 		FontType   string `yaml:"font_type"`
 	}
+	Theme  string        `yaml:"theme"`
+	Themes []ThemeConfig `yaml:"themes"`
 	Server struct {
 		AllowedHosts string `yaml:"allowed_hosts"`
 		Port         string `yaml:"port"`
@@ -49,9 +51,28 @@ type ApplicationConfig struct {
 			BaseUrl string `yaml:"baseUrl"`
 			Key     string `yaml:"key"`
 		} `yaml:"weather"`
+		// FontFallbacks fonts tried, in order, for glyphs a font doesn't have
+		FontFallbacks []string `yaml:"font_fallbacks"`
 	} `yaml:"data"`
 }
 
+// ThemeConfig a theme defined in the config file, see common.Theme for
+// what each field is for
+type ThemeConfig struct {
+	Name       string `yaml:"name"`
+	Background string `yaml:"background"`
+	Text       string `yaml:"text"`
+	Primary    string `yaml:"primary"`
+	Secondary  string `yaml:"secondary"`
+	Accent     string `yaml:"accent"`
+	Alert      string `yaml:"alert"`
+	Muted      string `yaml:"muted"`
+	Palette    string `yaml:"palette"`
+	Font       string `yaml:"font"`
+	FontStyle  string `yaml:"font_style"`
+	FontSize   int    `yaml:"font_size"`
+}
+
 var (
 	AppConfig = ApplicationConfig{}
 )
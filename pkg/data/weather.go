@@ -174,7 +174,7 @@ type WeatherForecastResponse struct {
 
 func FetchWeatherForecast(location string, days string) WeatherForecastResponse {
 	url := fmt.Sprintf(weatherForecastURL, WeatherClientConfig.Key, location, days)
-	fmt.Sprintf(url)
+	_ = url
 	// res := makeWeatherAPIRequest(url)
 	var respStruct WeatherForecastResponse
 	// json.Unmarshal(res, &respStruct)
@@ -0,0 +1,391 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"time"
+
+	"golang.org/x/image/webp"
+)
+
+// Animation a decoded animated image, with every frame drawn out to the
+// full canvas so frames can be shown on their own
+type Animation struct {
+	Frames    []image.Image
+	Delays    []time.Duration
+	LoopCount int // how many times to play the animation, 0 for forever
+}
+
+// how a frame's area is cleared before the next frame is drawn
+const (
+	disposeNone = iota
+	disposeBackground
+	disposePrevious
+)
+
+// animFrame a single frame as stored in the file, covering part of the canvas
+type animFrame struct {
+	img     image.Image
+	rect    image.Rectangle
+	delay   time.Duration
+	dispose int
+	over    bool // alpha blend onto the canvas rather than replacing it
+}
+
+// browsers treat delays this short as a mistake and show the frame for
+// defaultFrameDelay instead, and animations are authored with that in mind
+const (
+	minFrameDelay     = 10 * time.Millisecond
+	defaultFrameDelay = 100 * time.Millisecond
+)
+
+// DecodeAnimation decodes a GIF, WebP or PNG, including animated WebP and
+// APNG. Still images decode to a single frame
+func DecodeAnimation(data []byte) (*Animation, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("GIF8")):
+		return decodeGIF(data)
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return decodeWebP(data)
+	case bytes.HasPrefix(data, pngSignature):
+		return decodeAPNG(data)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Animation{Frames: []image.Image{img}, Delays: []time.Duration{0}}, nil
+}
+
+// compose draws each frame onto the canvas in turn, applying blending and
+// disposal, and returns the full canvas after each
+func compose(canvas image.Rectangle, frames []animFrame, loopCount int) *Animation {
+	anim := &Animation{LoopCount: loopCount}
+	current := image.NewRGBA(canvas)
+	for _, f := range frames {
+		var saved *image.RGBA
+		if f.dispose == disposePrevious {
+			saved = image.NewRGBA(f.rect)
+			draw.Draw(saved, f.rect, current, f.rect.Min, draw.Src)
+		}
+
+		op := draw.Src
+		if f.over {
+			op = draw.Over
+		}
+		draw.Draw(current, f.rect, f.img, f.img.Bounds().Min, op)
+
+		out := image.NewRGBA(canvas)
+		copy(out.Pix, current.Pix)
+		anim.Frames = append(anim.Frames, out)
+		delay := f.delay
+		if delay <= minFrameDelay {
+			delay = defaultFrameDelay
+		}
+		anim.Delays = append(anim.Delays, delay)
+
+		switch f.dispose {
+		case disposeBackground:
+			draw.Draw(current, f.rect, image.Transparent, image.Point{}, draw.Src)
+		case disposePrevious:
+			draw.Draw(current, f.rect, saved, f.rect.Min, draw.Src)
+		}
+	}
+	return anim
+}
+
+func decodeGIF(data []byte) (*Animation, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []animFrame
+	for ix, img := range g.Image {
+		f := animFrame{img: img, rect: img.Bounds(), delay: time.Duration(g.Delay[ix]) * 10 * time.Millisecond, over: true}
+		switch g.Disposal[ix] {
+		case gif.DisposalBackground:
+			f.dispose = disposeBackground
+		case gif.DisposalPrevious:
+			f.dispose = disposePrevious
+		}
+		frames = append(frames, f)
+	}
+
+	// gif counts repeats after the first play, -1 meaning none
+	loops := 0
+	switch {
+	case g.LoopCount < 0:
+		loops = 1
+	case g.LoopCount > 0:
+		loops = g.LoopCount + 1
+	}
+	canvas := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if canvas.Empty() && len(g.Image) > 0 {
+		canvas = g.Image[0].Bounds()
+	}
+	return compose(canvas, frames, loops), nil
+}
+
+// riffChunk a chunk of a WebP file
+type riffChunk struct {
+	id   string
+	data []byte
+}
+
+func riffChunks(data []byte) ([]riffChunk, error) {
+	var chunks []riffChunk
+	for len(data) >= 8 {
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		if size < 0 || 8+size > len(data) {
+			return nil, fmt.Errorf("webp chunk '%s' truncated", data[:4])
+		}
+		chunks = append(chunks, riffChunk{string(data[:4]), data[8 : 8+size]})
+		next := 8 + size + size%2
+		if next > len(data) {
+			next = len(data)
+		}
+		data = data[next:]
+	}
+	return chunks, nil
+}
+
+func u24(b []byte) int {
+	return int(b[0]) | int(b[1])<<8 | int(b[2])<<16
+}
+
+func decodeWebP(data []byte) (*Animation, error) {
+	chunks, err := riffChunks(data[12:])
+	if err != nil {
+		return nil, err
+	}
+
+	var canvas image.Rectangle
+	var frames []animFrame
+	loops := 0
+	for _, chunk := range chunks {
+		switch chunk.id {
+		case "VP8X":
+			if len(chunk.data) < 10 {
+				return nil, fmt.Errorf("webp VP8X chunk truncated")
+			}
+			canvas = image.Rect(0, 0, u24(chunk.data[4:])+1, u24(chunk.data[7:])+1)
+		case "ANIM":
+			if len(chunk.data) < 6 {
+				return nil, fmt.Errorf("webp ANIM chunk truncated")
+			}
+			loops = int(binary.LittleEndian.Uint16(chunk.data[4:]))
+		case "ANMF":
+			if len(chunk.data) < 16 {
+				return nil, fmt.Errorf("webp ANMF chunk truncated")
+			}
+			d := chunk.data
+			x, y := 2*u24(d[0:]), 2*u24(d[3:])
+			w, h := u24(d[6:])+1, u24(d[9:])+1
+			img, err := decodeWebPFrame(d[16:], w, h)
+			if err != nil {
+				return nil, err
+			}
+			f := animFrame{
+				img:   img,
+				rect:  image.Rect(x, y, x+w, y+h),
+				delay: time.Duration(u24(d[12:])) * time.Millisecond,
+				over:  d[15]&0x2 == 0,
+			}
+			if d[15]&0x1 != 0 {
+				f.dispose = disposeBackground
+			}
+			frames = append(frames, f)
+		}
+	}
+
+	if len(frames) == 0 {
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{Frames: []image.Image{img}, Delays: []time.Duration{0}}, nil
+	}
+	return compose(canvas, frames, loops), nil
+}
+
+// decodeWebPFrame decodes the image data of an animation frame by wrapping
+// it up as a standalone WebP file
+func decodeWebPFrame(data []byte, w int, h int) (image.Image, error) {
+	chunks, err := riffChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeChunk := func(id string, data []byte) {
+		body.WriteString(id)
+		binary.Write(&body, binary.LittleEndian, uint32(len(data)))
+		body.Write(data)
+		if len(data)%2 == 1 {
+			body.WriteByte(0)
+		}
+	}
+
+	hasAlpha := false
+	for _, chunk := range chunks {
+		hasAlpha = hasAlpha || chunk.id == "ALPH"
+	}
+	if hasAlpha {
+		vp8x := make([]byte, 10)
+		vp8x[0] = 1 << 4 // alpha
+		vp8x[4], vp8x[5], vp8x[6] = byte(w-1), byte((w-1)>>8), byte((w-1)>>16)
+		vp8x[7], vp8x[8], vp8x[9] = byte(h-1), byte((h-1)>>8), byte((h-1)>>16)
+		writeChunk("VP8X", vp8x)
+	}
+	for _, chunk := range chunks {
+		if chunk.id == "ALPH" || chunk.id == "VP8 " || chunk.id == "VP8L" {
+			writeChunk(chunk.id, chunk.data)
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	return webp.Decode(&file)
+}
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// apngFrame an APNG frame control chunk along with its image data
+type apngFrame struct {
+	animFrame
+	data []byte
+}
+
+func decodeAPNG(data []byte) (*Animation, error) {
+	var (
+		ihdr     []byte
+		shared   [][2][]byte // chunks every frame needs, like the palette
+		frames   []*apngFrame
+		current  *apngFrame
+		animated bool
+		loops    int
+		seenIDAT bool
+	)
+
+	for rest := data[len(pngSignature):]; len(rest) >= 12; {
+		size := int(binary.BigEndian.Uint32(rest))
+		if size < 0 || 12+size > len(rest) {
+			return nil, fmt.Errorf("png chunk truncated")
+		}
+		typ, body := string(rest[4:8]), rest[8:8+size]
+		rest = rest[12+size:]
+
+		switch typ {
+		case "IHDR":
+			if len(body) < 13 {
+				return nil, fmt.Errorf("png IHDR chunk truncated")
+			}
+			ihdr = body
+		case "acTL":
+			if len(body) < 8 {
+				return nil, fmt.Errorf("png acTL chunk truncated")
+			}
+			animated = true
+			loops = int(binary.BigEndian.Uint32(body[4:]))
+		case "fcTL":
+			if len(body) < 26 {
+				return nil, fmt.Errorf("png fcTL chunk truncated")
+			}
+			if ihdr == nil {
+				return nil, fmt.Errorf("png has no IHDR chunk before its image data")
+			}
+			w, h := int(binary.BigEndian.Uint32(body[4:])), int(binary.BigEndian.Uint32(body[8:]))
+			x, y := int(binary.BigEndian.Uint32(body[12:])), int(binary.BigEndian.Uint32(body[16:]))
+			num, den := binary.BigEndian.Uint16(body[20:]), binary.BigEndian.Uint16(body[22:])
+			if den == 0 {
+				den = 100
+			}
+			current = &apngFrame{animFrame: animFrame{
+				rect:    image.Rect(x, y, x+w, y+h),
+				delay:   time.Duration(num) * time.Second / time.Duration(den),
+				dispose: int(body[24]),
+				over:    body[25] == 1,
+			}}
+			frames = append(frames, current)
+		case "IDAT":
+			if ihdr == nil {
+				return nil, fmt.Errorf("png has no IHDR chunk before its image data")
+			}
+			seenIDAT = true
+			// the default image is only part of the animation when a
+			// frame control chunk comes before it
+			if current != nil {
+				current.data = append(current.data, body...)
+			}
+		case "fdAT":
+			if current != nil && len(body) >= 4 {
+				current.data = append(current.data, body[4:]...)
+			}
+		case "IEND":
+		default:
+			if !seenIDAT {
+				shared = append(shared, [2][]byte{[]byte(typ), body})
+			}
+		}
+	}
+
+	if !animated || len(frames) == 0 {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return &Animation{Frames: []image.Image{img}, Delays: []time.Duration{0}}, nil
+	}
+
+	var animFrames []animFrame
+	for ix, f := range frames {
+		img, err := decodeAPNGFrame(ihdr, shared, f)
+		if err != nil {
+			return nil, fmt.Errorf("apng frame %d: %v", ix, err)
+		}
+		f.img = img
+		if ix == 0 && f.dispose == disposePrevious {
+			f.dispose = disposeBackground
+		}
+		animFrames = append(animFrames, f.animFrame)
+	}
+	canvas := image.Rect(0, 0, int(binary.BigEndian.Uint32(ihdr)), int(binary.BigEndian.Uint32(ihdr[4:])))
+	return compose(canvas, animFrames, loops), nil
+}
+
+// decodeAPNGFrame decodes a frame by writing it out as a standalone PNG
+func decodeAPNGFrame(ihdr []byte, shared [][2][]byte, f *apngFrame) (image.Image, error) {
+	var buf bytes.Buffer
+	writeChunk := func(typ []byte, body []byte) {
+		binary.Write(&buf, binary.BigEndian, uint32(len(body)))
+		crc := crc32.NewIEEE()
+		crc.Write(typ)
+		crc.Write(body)
+		buf.Write(typ)
+		buf.Write(body)
+		binary.Write(&buf, binary.BigEndian, crc.Sum32())
+	}
+
+	header := append([]byte(nil), ihdr...)
+	binary.BigEndian.PutUint32(header, uint32(f.rect.Dx()))
+	binary.BigEndian.PutUint32(header[4:], uint32(f.rect.Dy()))
+
+	buf.Write(pngSignature)
+	writeChunk([]byte("IHDR"), header)
+	for _, chunk := range shared {
+		writeChunk(chunk[0], chunk[1])
+	}
+	writeChunk([]byte("IDAT"), f.data)
+	writeChunk([]byte("IEND"), nil)
+	return png.Decode(&buf)
+}
@@ -0,0 +1,134 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func solid(r image.Rectangle, c color.Color) *image.RGBA {
+	img := image.NewRGBA(r)
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestDecodeGIF(t *testing.T) {
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 255}
+	g := &gif.GIF{LoopCount: 2, Config: image.Config{Width: 4, Height: 4}}
+	for _, f := range []struct {
+		rect  image.Rectangle
+		c     color.Color
+		delay int
+	}{{image.Rect(0, 0, 4, 4), red, 5}, {image.Rect(2, 2, 4, 4), blue, 20}} {
+		frame := image.NewPaletted(f.rect, palette.Plan9)
+		draw.Draw(frame, f.rect, image.NewUniform(f.c), image.Point{}, draw.Src)
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, f.delay)
+		g.Disposal = append(g.Disposal, gif.DisposalNone)
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, gif.EncodeAll(&buf, g))
+
+	anim, err := DecodeAnimation(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 200 * time.Millisecond}, anim.Delays)
+	assert.Equal(t, 3, anim.LoopCount)
+	// the second frame only covers a corner, the rest shows through
+	assert.Equal(t, red, color.RGBAModel.Convert(anim.Frames[1].At(0, 0)))
+	assert.Equal(t, blue, color.RGBAModel.Convert(anim.Frames[1].At(3, 3)))
+}
+
+// apngChunk encodes a single png chunk
+func apngChunk(buf *bytes.Buffer, typ string, body []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(body)))
+	buf.WriteString(typ)
+	buf.Write(body)
+	binary.Write(buf, binary.BigEndian, crc32.ChecksumIEEE(append([]byte(typ), body...)))
+}
+
+// encodePNG returns the header and compressed image data of img encoded
+// as a png
+func encodePNG(t *testing.T, img image.Image) (ihdr []byte, idat []byte) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	data := buf.Bytes()[len(pngSignature):]
+	for len(data) >= 12 {
+		size := binary.BigEndian.Uint32(data)
+		switch string(data[4:8]) {
+		case "IHDR":
+			ihdr = data[8 : 8+size]
+		case "IDAT":
+			idat = append(idat, data[8:8+size]...)
+		}
+		data = data[12+size:]
+	}
+	return ihdr, idat
+}
+
+func TestDecodeAPNG(t *testing.T) {
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+	first := solid(image.Rect(0, 0, 4, 4), red)
+	second := solid(image.Rect(0, 0, 2, 2), green)
+
+	fcTL := func(seq, w, h, x, y int, num, den uint16, dispose, blend byte) []byte {
+		b := make([]byte, 26)
+		binary.BigEndian.PutUint32(b, uint32(seq))
+		binary.BigEndian.PutUint32(b[4:], uint32(w))
+		binary.BigEndian.PutUint32(b[8:], uint32(h))
+		binary.BigEndian.PutUint32(b[12:], uint32(x))
+		binary.BigEndian.PutUint32(b[16:], uint32(y))
+		binary.BigEndian.PutUint16(b[20:], num)
+		binary.BigEndian.PutUint16(b[22:], den)
+		b[24], b[25] = dispose, blend
+		return b
+	}
+
+	ihdr, firstData := encodePNG(t, first)
+	_, secondData := encodePNG(t, second)
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	apngChunk(&buf, "IHDR", ihdr)
+	apngChunk(&buf, "acTL", []byte{0, 0, 0, 2, 0, 0, 0, 0})
+	apngChunk(&buf, "fcTL", fcTL(0, 4, 4, 0, 0, 1, 4, 0, 0))
+	apngChunk(&buf, "IDAT", firstData)
+	apngChunk(&buf, "fcTL", fcTL(1, 2, 2, 1, 1, 0, 0, 0, 1))
+	apngChunk(&buf, "fdAT", append([]byte{0, 0, 0, 2}, secondData...))
+	apngChunk(&buf, "IEND", nil)
+
+	anim, err := DecodeAnimation(buf.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, anim.Frames, 2)
+	assert.Equal(t, 0, anim.LoopCount)
+	// a zero delay falls back to the default
+	assert.Equal(t, []time.Duration{250 * time.Millisecond, defaultFrameDelay}, anim.Delays)
+	assert.Equal(t, red, color.RGBAModel.Convert(anim.Frames[1].At(0, 0)))
+	assert.Equal(t, green, color.RGBAModel.Convert(anim.Frames[1].At(1, 1)))
+	assert.Equal(t, red, color.RGBAModel.Convert(anim.Frames[1].At(3, 3)))
+
+	// a short or missing header is an error rather than a panic
+	for name, header := range map[string][]byte{"short IHDR": ihdr[:4], "no IHDR": nil} {
+		var bad bytes.Buffer
+		bad.Write(pngSignature)
+		if header != nil {
+			apngChunk(&bad, "IHDR", header)
+		}
+		apngChunk(&bad, "acTL", []byte{0, 0, 0, 1, 0, 0, 0, 0})
+		apngChunk(&bad, "fcTL", fcTL(0, 4, 4, 0, 0, 1, 4, 0, 0))
+		apngChunk(&bad, "IDAT", firstData)
+		apngChunk(&bad, "IEND", nil)
+		_, err := DecodeAnimation(bad.Bytes())
+		assert.Error(t, err, name)
+	}
+}
@@ -0,0 +1,375 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// PCMFormat how raw PCM samples are laid out, for sources without a WAV
+// header to say so
+type PCMFormat struct {
+	Encoding   string // s16le (default), s24le, s32le, u8 or f32le
+	SampleRate int    // defaults to 44100
+	Channels   int    // interleaved, defaults to 2
+}
+
+func (f PCMFormat) withDefaults() PCMFormat {
+	if f.Encoding == "" {
+		f.Encoding = "s16le"
+	}
+	if f.SampleRate <= 0 {
+		f.SampleRate = 44100
+	}
+	if f.Channels <= 0 {
+		f.Channels = 2
+	}
+	return f
+}
+
+// frameSize bytes taken by one sample of every channel
+func (f PCMFormat) frameSize() (int, error) {
+	var size int
+	switch f.Encoding {
+	case "u8":
+		size = 1
+	case "s16le":
+		size = 2
+	case "s24le":
+		size = 3
+	case "s32le", "f32le":
+		size = 4
+	default:
+		return 0, fmt.Errorf("unknown PCM encoding '%s'", f.Encoding)
+	}
+	return size * f.Channels, nil
+}
+
+// decode converts one sample to -1 to 1
+func (f PCMFormat) decode(b []byte) float32 {
+	switch f.Encoding {
+	case "u8":
+		return (float32(b[0]) - 128) / 128
+	case "s16le":
+		return float32(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+	case "s24le":
+		return float32(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / (1 << 23)
+	case "s32le":
+		return float32(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+	case "f32le":
+		return math.Float32frombits(binary.LittleEndian.Uint32(b))
+	}
+	return 0
+}
+
+// AudioSource somewhere to get audio from to analyse
+type AudioSource interface {
+	// Samples returns the n samples of each channel leading up to at, the
+	// time since playback started. Live sources return the latest n
+	// whatever at is, with silence until enough has arrived
+	Samples(at time.Duration, n int) [][]float64
+	SampleRate() int
+	Channels() int
+}
+
+// AudioClip audio decoded into memory up front, played on a loop
+type AudioClip struct {
+	rate int
+	data [][]float32 // per channel
+}
+
+func (a *AudioClip) SampleRate() int { return a.rate }
+func (a *AudioClip) Channels() int   { return len(a.data) }
+
+// Duration how long the clip plays for before looping
+func (a *AudioClip) Duration() time.Duration {
+	if len(a.data) == 0 {
+		return 0
+	}
+	return time.Duration(len(a.data[0])) * time.Second / time.Duration(a.rate)
+}
+
+func (a *AudioClip) Samples(at time.Duration, n int) [][]float64 {
+	out := make([][]float64, len(a.data))
+	for ch, data := range a.data {
+		out[ch] = make([]float64, n)
+		if len(data) == 0 {
+			continue
+		}
+		end := int(math.Round(at.Seconds() * float64(a.rate)))
+		for i := range out[ch] {
+			j := (end - n + i) % len(data)
+			if j < 0 {
+				j += len(data)
+			}
+			out[ch][i] = float64(data[j])
+		}
+	}
+	return out
+}
+
+// DecodeWAV decodes a RIFF WAVE file holding integer or float PCM
+func DecodeWAV(data []byte) (*AudioClip, error) {
+	format, body, err := parseWAVHeader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	pcm, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePCM(pcm, format)
+}
+
+// DecodePCM decodes headerless interleaved PCM laid out as format says
+func DecodePCM(data []byte, format PCMFormat) (*AudioClip, error) {
+	format = format.withDefaults()
+	size, err := format.frameSize()
+	if err != nil {
+		return nil, err
+	}
+	frames := len(data) / size
+	clip := &AudioClip{rate: format.SampleRate, data: make([][]float32, format.Channels)}
+	step := size / format.Channels
+	for ch := range clip.data {
+		clip.data[ch] = make([]float32, frames)
+		for i := range clip.data[ch] {
+			off := i*size + ch*step
+			clip.data[ch][i] = format.decode(data[off : off+step])
+		}
+	}
+	return clip, nil
+}
+
+// parseWAVHeader reads up to the start of a WAV file's sample data,
+// returning its format and a reader positioned at the samples
+func parseWAVHeader(r io.Reader) (PCMFormat, io.Reader, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return PCMFormat{}, nil, err
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return PCMFormat{}, nil, errors.New("not a WAV file")
+	}
+
+	var format PCMFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return PCMFormat{}, nil, fmt.Errorf("WAV file has no data: %w", err)
+		}
+		id, size := string(chunk[:4]), binary.LittleEndian.Uint32(chunk[4:])
+		switch id {
+		case "fmt ":
+			body := make([]byte, size)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return PCMFormat{}, nil, err
+			}
+			if len(body) < 16 {
+				return PCMFormat{}, nil, errors.New("WAV format chunk too short")
+			}
+			tag := binary.LittleEndian.Uint16(body)
+			if tag == 0xFFFE && len(body) >= 26 {
+				// WAVE_FORMAT_EXTENSIBLE, the real tag starts the sub format GUID
+				tag = binary.LittleEndian.Uint16(body[24:])
+			}
+			format.Channels = int(binary.LittleEndian.Uint16(body[2:]))
+			format.SampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			if format.Channels <= 0 || format.SampleRate <= 0 {
+				return PCMFormat{}, nil, fmt.Errorf("WAV file has %d channels at %d Hz", format.Channels, format.SampleRate)
+			}
+			bits := binary.LittleEndian.Uint16(body[14:])
+			switch {
+			case tag == 1 && bits == 8:
+				format.Encoding = "u8"
+			case tag == 1 && bits == 16:
+				format.Encoding = "s16le"
+			case tag == 1 && bits == 24:
+				format.Encoding = "s24le"
+			case tag == 1 && bits == 32:
+				format.Encoding = "s32le"
+			case tag == 3 && bits == 32:
+				format.Encoding = "f32le"
+			default:
+				return PCMFormat{}, nil, fmt.Errorf("unsupported WAV format %d with %d bit samples", tag, bits)
+			}
+		case "data":
+			if format.Encoding == "" {
+				return PCMFormat{}, nil, errors.New("WAV data before its format")
+			}
+			// streamed WAVs give a size of 0 or 0xFFFFFFFF, read to the end
+			if size == 0 || size == math.MaxUint32 {
+				return format, r, nil
+			}
+			return format, io.LimitReader(r, int64(size)), nil
+		default:
+			// chunks are padded to an even length
+			if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+				return PCMFormat{}, nil, err
+			}
+		}
+	}
+}
+
+// AudioStream live audio read from a pipe as it arrives, keeping the
+// last few seconds for analysis
+type AudioStream struct {
+	mu     sync.Mutex
+	format PCMFormat
+	ring   [][]float32 // per channel
+	pos    int         // where the next sample goes
+}
+
+// audioStreamSeconds how much of a live stream is kept
+const audioStreamSeconds = 2
+
+// ReadAudioStream starts reading r in the background, a WAV stream if it
+// starts with a WAV header or raw PCM laid out as format says otherwise.
+// It reads until r ends or fails, logging why
+func ReadAudioStream(r io.Reader, format PCMFormat) *AudioStream {
+	s := &AudioStream{}
+	s.setFormat(format.withDefaults())
+	go s.read(r)
+	return s
+}
+
+func (s *AudioStream) setFormat(format PCMFormat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.format = format
+	s.ring = make([][]float32, format.Channels)
+	for ch := range s.ring {
+		s.ring[ch] = make([]float32, format.SampleRate*audioStreamSeconds)
+	}
+	s.pos = 0
+}
+
+func (s *AudioStream) read(r io.Reader) {
+	// a WAV header says what the format really is, waiting on it here
+	// rather than in ReadAudioStream since nothing may be writing yet
+	br := bufio.NewReader(r)
+	r = br
+	if head, err := br.Peek(4); err == nil && string(head) == "RIFF" {
+		format, body, err := parseWAVHeader(br)
+		if err != nil {
+			log.Printf("Error reading WAV stream: %v", err)
+			return
+		}
+		s.setFormat(format)
+		r = body
+	}
+
+	format := s.format
+	size, err := format.frameSize()
+	if err != nil {
+		log.Printf("Error reading audio stream: %v", err)
+		return
+	}
+	step := size / format.Channels
+	buf := make([]byte, size*1024)
+	for {
+		// only whole frames, so channels stay lined up
+		n, err := io.ReadAtLeast(r, buf, size)
+		n -= n % size
+		s.mu.Lock()
+		for off := 0; off < n; off += size {
+			for ch := range s.ring {
+				s.ring[ch][s.pos] = format.decode(buf[off+ch*step:])
+			}
+			s.pos = (s.pos + 1) % len(s.ring[0])
+		}
+		s.mu.Unlock()
+		if err != nil {
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				log.Printf("Error reading audio stream: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func (s *AudioStream) SampleRate() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.format.SampleRate
+}
+
+func (s *AudioStream) Channels() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.format.Channels
+}
+
+func (s *AudioStream) Samples(at time.Duration, n int) [][]float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([][]float64, len(s.ring))
+	for ch, ring := range s.ring {
+		out[ch] = make([]float64, n)
+		for i := range out[ch] {
+			j := (s.pos - n + i) % len(ring)
+			if j < 0 {
+				j += len(ring)
+			}
+			out[ch][i] = float64(ring[j])
+		}
+	}
+	return out
+}
+
+var (
+	audioStreams   = map[string]*AudioStream{}
+	audioStreamsMu sync.Mutex
+)
+
+// OpenAudio opens src for analysis. "-" reads stdin and named pipes (e.g.
+// an MPD fifo) are read live, both shared between everything that opens
+// them since a pipe can only be read once. Anything else is fetched like
+// any other file and decoded up front, a WAV or raw PCM laid out as
+// format says
+func OpenAudio(src string, format PCMFormat) (AudioSource, error) {
+	live := src == "-" || src == "stdin"
+	if !live {
+		if info, err := os.Stat(src); err == nil && info.Mode()&os.ModeNamedPipe != 0 {
+			live = true
+		}
+	}
+
+	if live {
+		audioStreamsMu.Lock()
+		defer audioStreamsMu.Unlock()
+		if s, ok := audioStreams[src]; ok {
+			return s, nil
+		}
+		var r io.Reader = os.Stdin
+		if src != "-" && src != "stdin" {
+			// opened read-write so the open doesn't block until a writer
+			// turns up, and the pipe isn't closed when one goes away
+			f, err := os.OpenFile(src, os.O_RDWR, 0)
+			if err != nil {
+				return nil, err
+			}
+			r = f
+		}
+		s := ReadAudioStream(r, format)
+		audioStreams[src] = s
+		return s, nil
+	}
+
+	data, _, err := FetchFile(src)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte("RIFF")) {
+		return DecodeWAV(data)
+	}
+	return DecodePCM(data, format)
+}
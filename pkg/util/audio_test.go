@@ -0,0 +1,128 @@
+package util
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wav builds a WAV file around interleaved samples
+func wav(tag uint16, channels int, rate int, bits int, samples []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+len(samples)))
+	buf.WriteString("WAVE")
+	// a chunk to skip, odd sized to check the padding
+	buf.WriteString("LIST")
+	binary.Write(&buf, binary.LittleEndian, uint32(3))
+	buf.Write([]byte{1, 2, 3, 0})
+	buf.WriteString("fmt ")
+	for _, v := range []interface{}{
+		uint32(16), tag, uint16(channels), uint32(rate),
+		uint32(rate * channels * bits / 8), uint16(channels * bits / 8), uint16(bits),
+	} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(len(samples)))
+	buf.Write(samples)
+	return buf.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, []int16{16384, -32768, 0, 32767})
+	clip, err := DecodeWAV(wav(1, 2, 8000, 16, pcm.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 8000, clip.SampleRate())
+	assert.Equal(t, 2, clip.Channels())
+	assert.Equal(t, 250*time.Microsecond, clip.Duration())
+	assert.Equal(t, [][]float64{{0.5, 0}, {-1, 32767.0 / 32768}}, clip.Samples(2*clip.Duration(), 2))
+
+	pcm.Reset()
+	binary.Write(&pcm, binary.LittleEndian, []float32{0.25, -0.75})
+	clip, err = DecodeWAV(wav(3, 1, 48000, 32, pcm.Bytes()))
+	require.NoError(t, err)
+	// loops round, and reads back from before the start
+	assert.Equal(t, [][]float64{{-0.75, 0.25, -0.75}}, clip.Samples(0, 3))
+
+	_, err = DecodeWAV(wav(2, 1, 8000, 4, nil))
+	assert.Error(t, err)
+	_, err = DecodeWAV([]byte("not audio"))
+	assert.Error(t, err)
+	_, err = DecodeWAV(wav(1, 0, 8000, 16, nil))
+	assert.Error(t, err)
+	_, err = DecodeWAV(wav(1, 1, 0, 16, nil))
+	assert.Error(t, err)
+}
+
+func TestDecodePCM(t *testing.T) {
+	clip, err := DecodePCM([]byte{128, 255, 0}, PCMFormat{Encoding: "u8", SampleRate: 100, Channels: 1})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0, 127.0 / 128, -1}}, clip.Samples(30*time.Millisecond, 3))
+
+	clip, err = DecodePCM([]byte{0, 0, 0x40, 0, 0, 0x80}, PCMFormat{Encoding: "s24le", Channels: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 44100, clip.SampleRate())
+	assert.Equal(t, [][]float64{{0.5, -1}}, clip.Samples(2*time.Second/44100, 2))
+
+	_, err = DecodePCM(nil, PCMFormat{Encoding: "mp3"})
+	assert.Error(t, err)
+}
+
+func TestReadAudioStream(t *testing.T) {
+	var pcm bytes.Buffer
+	binary.Write(&pcm, binary.LittleEndian, []int16{8192, 16384, -8192, -16384})
+	r, w := io.Pipe()
+	s := ReadAudioStream(r, PCMFormat{})
+	go func() {
+		w.Write(wav(1, 2, 1000, 16, pcm.Bytes()))
+		w.Close()
+	}()
+
+	// takes the format from the header once it arrives
+	want := [][]float64{{0, 0.25, -0.25}, {0, 0.5, -0.5}}
+	assert.Eventually(t, func() bool {
+		return s.SampleRate() == 1000 && assert.ObjectsAreEqual(want, s.Samples(0, 3))
+	}, time.Second, time.Millisecond)
+}
+
+func TestReadAudioStreamBadHeader(t *testing.T) {
+	// a header with no channels or no sample rate is logged and the stream
+	// stays silent, rather than panicking and taking the process with it
+	for _, header := range [][]byte{wav(1, 0, 1000, 16, make([]byte, 8)), wav(1, 2, 0, 16, make([]byte, 8))} {
+		s := ReadAudioStream(bytes.NewReader(header), PCMFormat{})
+		assert.Never(t, func() bool { return s.SampleRate() != 44100 }, 50*time.Millisecond, time.Millisecond)
+	}
+}
+
+func TestSpectrum(t *testing.T) {
+	const rate, n = 8000, 512
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = 0.5*math.Sin(2*math.Pi*1000*float64(i)/rate) + 0.1*math.Sin(2*math.Pi*250*float64(i)/rate)
+	}
+	spectrum := Spectrum(samples)
+	require.Len(t, spectrum, n/2)
+	// 1000Hz and 250Hz land right on bins 64 and 16
+	assert.InDelta(t, 0.5, spectrum[64], 0.01)
+	assert.InDelta(t, 0.1, spectrum[16], 0.01)
+	assert.Less(t, spectrum[40], 0.001)
+
+	// octaves from 125Hz, 250Hz and 1000Hz starting the second and fourth
+	bands := SpectrumBands(spectrum, rate, 4, 125, 2000)
+	assert.InDelta(t, 0.1, bands[1], 0.01)
+	assert.InDelta(t, 0.5, bands[3], 0.01)
+	assert.Less(t, bands[0], 0.1)
+	assert.Less(t, bands[2], bands[3])
+
+	assert.Equal(t, 1.0, Decibels(1, -60))
+	assert.InDelta(t, 0.5, Decibels(math.Pow(10, -30.0/20), -60), 1e-9)
+	assert.Equal(t, 0.0, Decibels(0.0001, -60))
+}
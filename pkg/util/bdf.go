@@ -0,0 +1,141 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// ParseBDF parses a font in the Glyph Bitmap Distribution Format
+func ParseBDF(data []byte) (*BitmapFont, error) {
+	f := newBitmapFont()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	var (
+		encoding  = -1
+		advance   int
+		bbx       [4]int
+		inBitmap  bool
+		rows      []string
+		lineNum   int
+		sawHeader bool
+	)
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBitmap {
+			if fields[0] != "ENDCHAR" {
+				rows = append(rows, fields[0])
+				continue
+			}
+			inBitmap = false
+			if encoding < 0 {
+				// unencoded glyph, nothing can reference it
+				continue
+			}
+			glyph, err := bdfGlyph(rows, bbx, advance)
+			if err != nil {
+				return nil, fmt.Errorf("bdf line %d: %v", lineNum, err)
+			}
+			f.glyphs[rune(encoding)] = glyph
+			continue
+		}
+
+		ints, err := atois(fields[1:])
+		switch fields[0] {
+		case "STARTFONT":
+			sawHeader = true
+		case "FONT_ASCENT":
+			if err == nil && len(ints) == 1 {
+				f.ascent = ints[0]
+			}
+		case "FONT_DESCENT":
+			if err == nil && len(ints) == 1 {
+				f.descent = ints[0]
+			}
+		case "DEFAULT_CHAR":
+			if err == nil && len(ints) == 1 {
+				f.defaultChar = rune(ints[0])
+			}
+		case "STARTCHAR":
+			encoding, advance, bbx = -1, 0, [4]int{}
+		case "ENCODING":
+			if err != nil || len(ints) < 1 {
+				return nil, fmt.Errorf("bdf line %d: bad ENCODING", lineNum)
+			}
+			encoding = ints[0]
+		case "DWIDTH":
+			if err != nil || len(ints) < 1 {
+				return nil, fmt.Errorf("bdf line %d: bad DWIDTH", lineNum)
+			}
+			advance = ints[0]
+		case "BBX":
+			if err != nil || len(ints) != 4 {
+				return nil, fmt.Errorf("bdf line %d: bad BBX", lineNum)
+			}
+			copy(bbx[:], ints)
+		case "BITMAP":
+			inBitmap = true
+			rows = rows[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !sawHeader {
+		return nil, fmt.Errorf("not a bdf font")
+	}
+
+	f.finish()
+	return f, nil
+}
+
+// bdfGlyph builds a glyph from its hex encoded rows and BBX values
+// (width, height, x offset, y offset from the baseline)
+func bdfGlyph(rows []string, bbx [4]int, advance int) (*bitmapGlyph, error) {
+	w, h := bbx[0], bbx[1]
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	for y := 0; y < h && y < len(rows); y++ {
+		row := rows[y]
+		for x := 0; x < w; x++ {
+			nibble := x / 4
+			if nibble >= len(row) {
+				break
+			}
+			v, err := strconv.ParseUint(row[nibble:nibble+1], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("bad bitmap row '%s'", row)
+			}
+			if v&(0x8>>(x%4)) != 0 {
+				mask.Pix[y*mask.Stride+x] = 0xff
+			}
+		}
+	}
+
+	return &bitmapGlyph{
+		mask:    mask,
+		offset:  image.Pt(bbx[2], -(bbx[3] + h)),
+		advance: advance,
+	}, nil
+}
+
+func atois(fields []string) ([]int, error) {
+	ints := make([]int, len(fields))
+	for i, field := range fields {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		ints[i] = v
+	}
+	return ints, nil
+}
@@ -0,0 +1,78 @@
+package util
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// a 4x6 font with a single "A" glyph
+const testBDF = `STARTFONT 2.1
+FONT -test-fixed-medium-r-normal--6-60-75-75-c-40-iso10646-1
+SIZE 6 75 75
+FONTBOUNDINGBOX 4 6 0 -1
+STARTPROPERTIES 2
+FONT_ASCENT 5
+FONT_DESCENT 1
+ENDPROPERTIES
+CHARS 1
+STARTCHAR A
+ENCODING 65
+SWIDTH 640 0
+DWIDTH 4 0
+BBX 3 5 0 0
+BITMAP
+40
+A0
+E0
+A0
+A0
+ENDCHAR
+ENDFONT
+`
+
+func TestParseBDF(t *testing.T) {
+	face, err := ParseBDF([]byte(testBDF))
+	assert.NoError(t, err)
+
+	assert.True(t, face.HasGlyph('A'))
+	assert.False(t, face.HasGlyph('B'))
+	assert.Equal(t, fixed.I(5), face.Metrics().Ascent)
+	assert.Equal(t, fixed.I(6), face.Metrics().Height)
+
+	advance, ok := face.GlyphAdvance('A')
+	assert.True(t, ok)
+	assert.Equal(t, fixed.I(4), advance)
+
+	// draw and check the pixels are exact, with no anti-aliasing
+	dst := image.NewGray(image.Rect(0, 0, 4, 6))
+	d := font.Drawer{Dst: dst, Src: image.White, Face: face, Dot: fixed.P(0, 5)}
+	d.DrawString("A")
+
+	want := []string{
+		".#..",
+		"#.#.",
+		"###.",
+		"#.#.",
+		"#.#.",
+		"....",
+	}
+	for y, row := range want {
+		for x, ch := range row {
+			v := dst.GrayAt(x, y).Y
+			if ch == '#' {
+				assert.Equal(t, uint8(0xff), v, "pixel %d,%d", x, y)
+			} else {
+				assert.Equal(t, uint8(0), v, "pixel %d,%d", x, y)
+			}
+		}
+	}
+}
+
+func TestParseBDFInvalid(t *testing.T) {
+	_, err := ParseBDF([]byte("not a font"))
+	assert.Error(t, err)
+}
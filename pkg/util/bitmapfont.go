@@ -0,0 +1,101 @@
+package util
+
+import (
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// BitmapFont is a font.Face backed by pre-rendered glyph bitmaps, as loaded
+// from BDF or PCF files. Glyphs are drawn at their native pixel size with no
+// anti-aliasing, which keeps small text crisp on a LED matrix
+type BitmapFont struct {
+	glyphs      map[rune]*bitmapGlyph
+	defaultChar rune
+	ascent      int
+	descent     int
+}
+
+// bitmapGlyph a single glyph's mask, positioned relative to the dot
+type bitmapGlyph struct {
+	mask    *image.Alpha
+	offset  image.Point // top-left of the mask relative to the baseline origin
+	advance int
+}
+
+func newBitmapFont() *BitmapFont {
+	return &BitmapFont{
+		glyphs:      make(map[rune]*bitmapGlyph),
+		defaultChar: -1,
+	}
+}
+
+// HasGlyph reports whether the font has its own glyph for r
+func (f *BitmapFont) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// glyph looks up the glyph for r, falling back to the font's default char
+func (f *BitmapFont) glyph(r rune) (*bitmapGlyph, bool) {
+	if g, ok := f.glyphs[r]; ok {
+		return g, true
+	}
+	g, ok := f.glyphs[f.defaultChar]
+	return g, ok
+}
+
+func (f *BitmapFont) Close() error { return nil }
+
+func (f *BitmapFont) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	g, ok := f.glyph(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	origin := image.Pt(dot.X.Round(), dot.Y.Round()).Add(g.offset)
+	dr := g.mask.Bounds().Add(origin)
+	return dr, g.mask, image.Point{}, fixed.I(g.advance), true
+}
+
+func (f *BitmapFont) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	g, ok := f.glyph(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+	b := g.mask.Bounds().Add(g.offset)
+	return fixed.R(b.Min.X, b.Min.Y, b.Max.X, b.Max.Y), fixed.I(g.advance), true
+}
+
+func (f *BitmapFont) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	g, ok := f.glyph(r)
+	if !ok {
+		return 0, false
+	}
+	return fixed.I(g.advance), true
+}
+
+func (f *BitmapFont) Kern(r0, r1 rune) fixed.Int26_6 { return 0 }
+
+func (f *BitmapFont) Metrics() font.Metrics {
+	return font.Metrics{
+		Height:  fixed.I(f.ascent + f.descent),
+		Ascent:  fixed.I(f.ascent),
+		Descent: fixed.I(f.descent),
+	}
+}
+
+// finish fills in any metrics the font file didn't declare
+func (f *BitmapFont) finish() {
+	if f.ascent != 0 || f.descent != 0 {
+		return
+	}
+	for _, g := range f.glyphs {
+		if -g.offset.Y > f.ascent {
+			f.ascent = -g.offset.Y
+		}
+		if bottom := g.offset.Y + g.mask.Bounds().Dy(); bottom > f.descent {
+			f.descent = bottom
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"image"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// fallbackFace a chain of faces where each glyph comes from the first face
+// that has it, so symbols, accented names and emoji missing from a font
+// still draw. Metrics come from the first face. TrueType faces keep a glyph
+// cache, so the whole chain is locked to make it safe to share
+type fallbackFace struct {
+	mu    sync.Mutex
+	faces []font.Face
+	has   []func(rune) bool
+}
+
+func (f *fallbackFace) add(face font.Face, has func(rune) bool) {
+	f.faces = append(f.faces, face)
+	f.has = append(f.has, has)
+}
+
+// face the face to draw r with, the first one when none have it so it's
+// drawn as that font's missing glyph
+func (f *fallbackFace) face(r rune) font.Face {
+	for i, has := range f.has {
+		if has(r) {
+			return f.faces[i]
+		}
+	}
+	return f.faces[0]
+}
+
+// HasGlyph reports whether any face in the chain has a glyph for r
+func (f *fallbackFace) HasGlyph(r rune) bool {
+	for _, has := range f.has {
+		if has(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fallbackFace) Close() error { return nil }
+
+func (f *fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dr, mask, maskp, advance, ok := f.face(r).Glyph(dot, r)
+	if mask == nil {
+		return dr, mask, maskp, advance, ok
+	}
+	// truetype draws every glyph into the same buffer, copy this one out
+	// before another caller can overwrite it
+	glyph := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	draw.Draw(glyph, glyph.Bounds(), mask, maskp, draw.Src)
+	return dr, glyph, image.Point{}, advance, ok
+}
+
+func (f *fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f *fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face(r).GlyphAdvance(r)
+}
+
+// Kern only kerns pairs drawn from the same face
+func (f *fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	face := f.face(r0)
+	if face != f.face(r1) {
+		return 0
+	}
+	return face.Kern(r0, r1)
+}
+
+func (f *fallbackFace) Metrics() font.Metrics {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.faces[0].Metrics()
+}
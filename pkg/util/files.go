@@ -6,7 +6,6 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"github.com/nfnt/resize"
 	"image"
 	"image/color/palette"
 	"image/draw"
@@ -24,6 +23,11 @@ import (
 
 // Like FetchFile, but will resize images and redraw gifs
 func FetchImage(file string, x int, y int) ([]byte, string, error) {
+	return FetchImageFit(file, x, y, ImageFit{})
+}
+
+// Like FetchImage, but scales the image into the box as described by fit
+func FetchImageFit(file string, x int, y int, fit ImageFit) ([]byte, string, error) {
 	// use FetchFile to get raw image
 	var rawData []byte
 	var rawFilePath string
@@ -41,7 +45,7 @@ func FetchImage(file string, x int, y int) ([]byte, string, error) {
 	if extension == ".svg" {
 		filename = fmt.Sprintf("%s%s", baseName, extension)
 	} else {
-		filename = fmt.Sprintf("%s_%dx%d%s", baseName, x, y, extension)
+		filename = fmt.Sprintf("%s_%dx%d%s%s", baseName, x, y, fit.key(), extension)
 	}
 
 	fmt.Println(filename, extension)
@@ -85,7 +89,7 @@ func FetchImage(file string, x int, y int) ([]byte, string, error) {
 				draw.Draw(newFrame, frame.Bounds(), frame, bounds.Min, draw.Over)
 
 				// resize
-				resizedImg := resize.Resize(uint(x), uint(y), newFrame, resize.Lanczos3)
+				resizedImg := FitImage(newFrame, x, y, fit)
 				// Convert the resized image.Image to *image.Paletted
 				resizedBounds := resizedImg.Bounds()
 				palettedImage := image.NewPaletted(resizedBounds, frame.Palette)
@@ -114,18 +118,18 @@ func FetchImage(file string, x int, y int) ([]byte, string, error) {
 			if err != nil {
 				log.Fatal(err)
 			}
-			img = resize.Resize(uint(x), uint(y), img, resize.Lanczos3)
+			img = FitImage(img, x, y, fit)
 			err = png.Encode(outFile, img)
 			if err != nil {
 				log.Fatalf("Failed to encode image: %s", err)
 			}
-		} else if extension == ".jpg" || extension == "jpeg" {
+		} else if extension == ".jpg" || extension == ".jpeg" {
 			img, _, err := image.Decode(bytes.NewReader(rawData))
 			if err != nil {
 				log.Fatal(err)
 			}
 			// Resize the image
-			img = resize.Resize(uint(x), uint(y), img, resize.Lanczos3)
+			img = FitImage(img, x, y, fit)
 
 			// Encode the image to the outFile as a JPEG
 			err = jpeg.Encode(outFile, img, nil) // nil means use the default quality settings
@@ -3,22 +3,355 @@ package util
 import (
 	"fmt"
 	"github.com/golang/freetype/truetype"
-	"io/ioutil"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"io/fs"
 	"log"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 )
 
+// FontInfo a font found in the font directory, or one of the built in Go
+// fonts everything falls back to
+type FontInfo struct {
+	Name   string `json:"name"` // file name without the extension, e.g. Ubuntu-Bold
+	Family string `json:"family"`
+	Style  string `json:"style"`
+	Format string `json:"format"` // ttf, otf, bdf or pcf
+	Path   string `json:"-"`
+}
+
+// Bitmap reports whether the font is drawn at its native pixel size
+func (f FontInfo) Bitmap() bool {
+	return f.Format == "bdf" || f.Format == "pcf"
+}
+
+// fontExts file extensions scanned for, bitmap fonts first so they win
+// over a TrueType font with the same name
+var fontExts = []struct{ ext, format string }{
+	{".bdf", "bdf"},
+	{".pcf.gz", "pcf"},
+	{".pcf", "pcf"},
+	{".ttf", "ttf"},
+	{".otf", "otf"},
+}
+
+// builtinFonts always available, the last resort when nothing else has a
+// glyph or a font can't be found
+var builtinFonts = map[string][]byte{
+	"Go-Regular": goregular.TTF,
+	"Go-Bold":    gobold.TTF,
+	"Go-Mono":    gomono.TTF,
+}
+
+const lastResortFont = "Go-Regular"
+
+// fonts the font registry, what's in the font directory and the fonts and
+// faces loaded from it, shared by every component so refreshing a template
+// doesn't read them from disk again
+var fonts = struct {
+	sync.Mutex
+	dir      string // the directory scanned, rescanned if the config moves
+	scanned  bool
+	infos    map[string]FontInfo // by lower case name
+	truetype map[string]*truetype.Font
+	bitmap   map[string]*BitmapFont
+	faces    map[string]font.Face // by font chain and size
+}{}
+
+// ScanFonts looks through the font directory, and its subdirectories, for
+// fonts. It's done on first use and again whenever the font directory
+// changes, calling it at startup just gets it out of the way
+func ScanFonts() []FontInfo {
+	fonts.Lock()
+	defer fonts.Unlock()
+	fonts.scanned = false
+	return scanFonts()
+}
+
+// scanFonts rescans if needed and lists what was found, fonts must be
+// locked
+func scanFonts() []FontInfo {
+	if !fonts.scanned || fonts.dir != Config.FontDir {
+		fonts.dir, fonts.scanned = Config.FontDir, true
+		fonts.infos = map[string]FontInfo{}
+		fonts.truetype = map[string]*truetype.Font{}
+		fonts.bitmap = map[string]*BitmapFont{}
+		fonts.faces = map[string]font.Face{}
+
+		for name := range builtinFonts {
+			fonts.infos[strings.ToLower(name)] = newFontInfo(name, "ttf", "")
+		}
+		if fonts.dir != "" {
+			found := map[string]FontInfo{}
+			err := filepath.WalkDir(fonts.dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() {
+					return err
+				}
+				for i, ext := range fontExts {
+					if !strings.HasSuffix(strings.ToLower(path), ext.ext) {
+						continue
+					}
+					name := filepath.Base(path)
+					name = name[:len(name)-len(ext.ext)]
+					key := strings.ToLower(name)
+					if prev, ok := found[key]; !ok || formatRank(prev.Format) > i {
+						found[key] = newFontInfo(name, ext.format, path)
+					}
+					break
+				}
+				return nil
+			})
+			if err != nil {
+				log.Printf("Unable to scan fonts in %s: %v", fonts.dir, err)
+			}
+			for key, info := range found {
+				fonts.infos[key] = info
+			}
+		}
+	}
+
+	infos := make([]FontInfo, 0, len(fonts.infos))
+	for _, info := range fonts.infos {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+func newFontInfo(name string, format string, path string) FontInfo {
+	info := FontInfo{Name: name, Family: name, Format: format, Path: path}
+	if i := strings.LastIndex(name, "-"); i > 0 {
+		info.Family, info.Style = name[:i], name[i+1:]
+	}
+	return info
+}
+
+// formatRank where a format falls in fontExts
+func formatRank(format string) int {
+	for i, ext := range fontExts {
+		if ext.format == format {
+			return i
+		}
+	}
+	return len(fontExts)
+}
+
+// Fonts lists the fonts available, built in ones included
+func Fonts() []FontInfo {
+	fonts.Lock()
+	defer fonts.Unlock()
+	return scanFonts()
+}
+
+// FindFont looks up a font by name, e.g. Ubuntu-Bold. Case doesn't matter,
+// a family on its own finds its Regular style, and a style the family
+// doesn't have falls back to its Regular style or whichever it has
+func FindFont(name string) (FontInfo, bool) {
+	fonts.Lock()
+	defer fonts.Unlock()
+	scanFonts()
+	return findFont(name)
+}
+
+func findFont(name string) (FontInfo, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if info, ok := fonts.infos[key]; ok {
+		return info, true
+	}
+
+	family := key
+	if i := strings.LastIndex(key, "-"); i > 0 {
+		family = key[:i]
+	}
+	if info, ok := fonts.infos[family+"-regular"]; ok {
+		return info, true
+	}
+	if info, ok := fonts.infos[family]; ok {
+		return info, true
+	}
+	var match *FontInfo
+	for _, info := range fonts.infos {
+		if strings.ToLower(info.Family) == family && (match == nil || info.Name < match.Name) {
+			info := info
+			match = &info
+		}
+	}
+	if match != nil {
+		return *match, true
+	}
+	return FontInfo{}, false
+}
+
+// LoadFont loads a TrueType font by name, falling back to Go-Regular when
+// it can't be found or isn't a TrueType font
 func LoadFont(fontName string) *truetype.Font {
-	// Read font file from disk
-	filePath := filepath.Join(Config.FontDir, fmt.Sprintf("%s.ttf", fontName))
-	fontBytes, err := ioutil.ReadFile(filePath)
+	fonts.Lock()
+	defer fonts.Unlock()
+	scanFonts()
+
+	info, ok := findFont(fontName)
+	if !ok || info.Bitmap() {
+		log.Printf("No TrueType font '%s', using %s", fontName, lastResortFont)
+		info = fonts.infos[strings.ToLower(lastResortFont)]
+	}
+	f, err := loadTrueType(info)
 	if err != nil {
-		log.Fatalf("Failed to read font file: %v", err)
+		log.Printf("Unable to load font '%s', using %s: %v", fontName, lastResortFont, err)
+		f, _ = loadTrueType(fonts.infos[strings.ToLower(lastResortFont)])
 	}
-	// Parse font file into a truetype.Font
-	font, err := truetype.Parse(fontBytes)
+	return f
+}
+
+// loadTrueType parses a TrueType font, fonts must be locked
+func loadTrueType(info FontInfo) (*truetype.Font, error) {
+	if f, ok := fonts.truetype[info.Name]; ok {
+		return f, nil
+	}
+	data, ok := builtinFonts[info.Name]
+	if !ok || info.Path != "" {
+		var err error
+		if data, err = os.ReadFile(info.Path); err != nil {
+			return nil, err
+		}
+	}
+	f, err := truetype.Parse(data)
 	if err != nil {
-		log.Fatalf("Failed to parse font file: %v", err)
+		return nil, err
+	}
+	fonts.truetype[info.Name] = f
+	return f, nil
+}
+
+// loadBitmap parses a BDF or PCF font, fonts must be locked
+func loadBitmap(info FontInfo) (*BitmapFont, error) {
+	if f, ok := fonts.bitmap[info.Name]; ok {
+		return f, nil
+	}
+	data, err := os.ReadFile(info.Path)
+	if err != nil {
+		return nil, err
+	}
+	parse := ParsePCF
+	if info.Format == "bdf" {
+		parse = ParseBDF
+	}
+	f, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	fonts.bitmap[info.Name] = f
+	return f, nil
+}
+
+// FontName joins a font and style into the name used on disk,
+// "<Font>-<Style>", or just "<Font>" when there is no style. A comma
+// separated list of fonts, a fallback chain, gets the style on each
+func FontName(font string, style string) string {
+	if style == "" {
+		return font
+	}
+	names := strings.Split(font, ",")
+	for i, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			names[i] = fmt.Sprintf("%s-%s", name, style)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// LoadFace returns a face for the given font name ("<Font>-<Style>"), or a
+// comma separated fallback chain of them. Glyphs the first font doesn't
+// have come from the next one that does, then from the configured
+// fallbacks and lastly Go-Regular. Bitmap fonts (.bdf or .pcf) are always
+// drawn at their native pixel size, so size only applies to TrueType fonts.
+// Faces are cached per font and size and safe to share
+func LoadFace(fontName string, size float64) font.Face {
+	fonts.Lock()
+	defer fonts.Unlock()
+	scanFonts()
+
+	key := fmt.Sprintf("%s/%.2f", fontName, size)
+	if face, ok := fonts.faces[key]; ok {
+		return face
+	}
+
+	names := append(strings.Split(fontName, ","), Config.FontFallbacks...)
+	names = append(names, lastResortFont)
+	face := &fallbackFace{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		info, ok := findFont(name)
+		if !ok {
+			log.Printf("No font named '%s'", strings.TrimSpace(name))
+			continue
+		}
+		if seen[info.Name] {
+			continue
+		}
+		seen[info.Name] = true
+
+		if info.Bitmap() {
+			f, err := loadBitmap(info)
+			if err != nil {
+				log.Printf("Unable to load font %s: %v", info.Path, err)
+				continue
+			}
+			face.add(f, f.HasGlyph)
+			continue
+		}
+		f, err := loadTrueType(info)
+		if err != nil {
+			log.Printf("Unable to load font %s: %v", info.Path, err)
+			continue
+		}
+		face.add(truetype.NewFace(f, &truetype.Options{Size: size, Hinting: font.HintingNone}), func(r rune) bool {
+			return f.Index(r) != 0
+		})
+	}
+	fonts.faces[key] = face
+	return face
+}
+
+// FontPreview draws text in the named font, white on black, with each
+// pixel blown up to a scale by scale square so small fonts are readable
+func FontPreview(fontName string, text string, size float64, scale int) image.Image {
+	face := LoadFace(fontName, size)
+	metrics := face.Metrics()
+	const margin = 1
+	w := font.MeasureString(face, text).Ceil() + 2*margin
+	h := (metrics.Ascent + metrics.Descent).Ceil() + 2*margin
+
+	im := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(im, im.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	d := font.Drawer{
+		Dst:  im,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(margin, margin+metrics.Ascent.Ceil()),
+	}
+	d.DrawString(text)
+
+	if scale <= 1 {
+		return im
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, w*scale, h*scale))
+	for y := 0; y < h*scale; y++ {
+		for x := 0; x < w*scale; x++ {
+			scaled.Set(x, y, im.At(x/scale, y/scale))
+		}
 	}
-	return font
+	return scaled
 }
@@ -0,0 +1,74 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestFonts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pixel"), 0o755))
+	for name, data := range map[string][]byte{
+		"Sans-Bold.ttf":      gobold.TTF,
+		"pixel/Tiny-4x6.bdf": []byte(testBDF),
+		"notes.txt":          []byte("not a font"),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o644))
+	}
+	prev := Config
+	SetUtilConfig(&UtilConfig{FontDir: dir})
+	defer SetUtilConfig(prev)
+
+	names := []string{}
+	for _, info := range ScanFonts() {
+		names = append(names, info.Name)
+	}
+	assert.Equal(t, []string{"Go-Bold", "Go-Mono", "Go-Regular", "Sans-Bold", "Tiny-4x6"}, names)
+
+	// loose names find the closest font
+	for name, want := range map[string]string{
+		"sans-bold":    "Sans-Bold",
+		"Sans":         "Sans-Bold",
+		"Sans-Italic":  "Sans-Bold",
+		"Go-Italic":    "Go-Regular",
+		"Tiny":         "Tiny-4x6",
+		"tiny-4x6 ":    "Tiny-4x6",
+		"Go-Regular":   "Go-Regular",
+		"Sans-Regular": "Sans-Bold",
+	} {
+		info, ok := FindFont(name)
+		if assert.True(t, ok, name) {
+			assert.Equal(t, want, info.Name, name)
+		}
+	}
+	_, ok := FindFont("Missing")
+	assert.False(t, ok)
+
+	// the bitmap font only has an A, the rest fall back down the chain
+	face := LoadFace("Tiny-4x6", 12)
+	assert.Same(t, face, LoadFace("Tiny-4x6", 12))
+	assert.Equal(t, fixed.I(5), face.Metrics().Ascent)
+	advance, _ := face.GlyphAdvance('A')
+	assert.Equal(t, fixed.I(4), advance)
+	advance, ok = face.GlyphAdvance('é')
+	assert.True(t, ok)
+	assert.NotEqual(t, fixed.I(4), advance)
+	assert.True(t, face.(*fallbackFace).HasGlyph('é'))
+	assert.False(t, face.(*fallbackFace).HasGlyph('★'))
+
+	// a chain picks the first font with the glyph, and missing fonts don't
+	// stop the app
+	chain := LoadFace(FontName("Missing, Tiny", "4x6"), 12).(*fallbackFace)
+	assert.Len(t, chain.faces, 2)
+	assert.NotNil(t, LoadFont("Missing"))
+
+	preview := FontPreview("Tiny-4x6", "AA", 6, 2)
+	assert.Equal(t, 2*(2*4+2), preview.Bounds().Dx())
+	assert.Equal(t, 2*(6+2), preview.Bounds().Dy())
+}
@@ -0,0 +1,133 @@
+package util
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"math"
+)
+
+// ImageFilter color adjustments applied to an image after it's scaled
+type ImageFilter struct {
+	Grayscale  bool
+	Tint       color.RGBA // multiplies each pixel, ignored when fully transparent
+	Brightness float64    // added to each channel, -1 to 1
+	Contrast   float64    // scales each channel around the midpoint, 1 leaves it unchanged
+	Dither     string     // palette to dither to: bw, gray4, rgb8, rgb332, websafe or plan9
+}
+
+// ditherPalette a fixed palette along with how far apart its colors are,
+// which sets the strength of the dither pattern
+type ditherPalette struct {
+	colors color.Palette
+	spread float64
+}
+
+// ditherPalettes the palettes images can be dithered to
+var ditherPalettes = map[string]ditherPalette{
+	"bw":      {color.Palette{color.Black, color.White}, 255},
+	"gray4":   {grayPalette(4), 85},
+	"rgb8":    {rgbPalette(2, 2, 2), 255},
+	"rgb332":  {rgbPalette(8, 8, 4), 36},
+	"websafe": {palette.WebSafe, 51},
+	"plan9":   {palette.Plan9, 32},
+}
+
+// bayer4 a 4x4 ordered dither threshold map
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+func grayPalette(levels int) color.Palette {
+	var p color.Palette
+	for i := 0; i < levels; i++ {
+		v := uint8(i * 255 / (levels - 1))
+		p = append(p, color.RGBA{v, v, v, 255})
+	}
+	return p
+}
+
+func rgbPalette(rl, gl, bl int) color.Palette {
+	var p color.Palette
+	for r := 0; r < rl; r++ {
+		for g := 0; g < gl; g++ {
+			for b := 0; b < bl; b++ {
+				p = append(p, color.RGBA{
+					uint8(r * 255 / (rl - 1)),
+					uint8(g * 255 / (gl - 1)),
+					uint8(b * 255 / (bl - 1)),
+					255,
+				})
+			}
+		}
+	}
+	return p
+}
+
+// IsZero reports whether the filter leaves images unchanged
+func (f ImageFilter) IsZero() bool {
+	return !f.Grayscale && f.Tint.A == 0 && f.Brightness == 0 &&
+		(f.Contrast == 0 || f.Contrast == 1) && f.Dither == ""
+}
+
+// Apply returns a filtered copy of img
+func (f ImageFilter) Apply(img image.Image) image.Image {
+	if f.IsZero() {
+		return img
+	}
+
+	b := img.Bounds()
+	out := image.NewNRGBA(b)
+	draw.Draw(out, b, img, b.Min, draw.Src)
+
+	contrast := f.Contrast
+	if contrast == 0 {
+		contrast = 1
+	}
+	dither, doDither := ditherPalettes[f.Dither]
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := out.PixOffset(x, y)
+			px := out.Pix[i : i+4 : i+4]
+			if px[3] == 0 {
+				continue
+			}
+			c := [3]float64{float64(px[0]), float64(px[1]), float64(px[2])}
+
+			if f.Grayscale {
+				l := 0.299*c[0] + 0.587*c[1] + 0.114*c[2]
+				c = [3]float64{l, l, l}
+			}
+			if f.Tint.A > 0 {
+				c[0] *= float64(f.Tint.R) / 255
+				c[1] *= float64(f.Tint.G) / 255
+				c[2] *= float64(f.Tint.B) / 255
+			}
+			for ch := range c {
+				c[ch] = (c[ch]-127.5)*contrast + 127.5 + f.Brightness*255
+			}
+			if doDither {
+				threshold := (bayer4[y&3][x&3]/16 - 0.5) * dither.spread
+				for ch := range c {
+					c[ch] += threshold
+				}
+			}
+
+			rgba := color.RGBA{clamp8(c[0]), clamp8(c[1]), clamp8(c[2]), 255}
+			if doDither {
+				rgba = color.RGBAModel.Convert(dither.colors.Convert(rgba)).(color.RGBA)
+			}
+			px[0], px[1], px[2] = rgba.R, rgba.G, rgba.B
+		}
+	}
+	return out
+}
+
+func clamp8(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
@@ -0,0 +1,96 @@
+package util
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+
+	"github.com/nfnt/resize"
+)
+
+// ImageFit controls how an image is scaled into a box
+type ImageFit struct {
+	Fit     string          // stretch (default), contain, cover or none
+	FocusX  float64         // where to anchor horizontally when the image doesn't fill the box, 0 to 1
+	FocusY  float64         // where to anchor vertically, 0 to 1
+	Crop    image.Rectangle // region of the source image to use, empty for all of it
+	Nearest bool            // nearest-neighbor scaling, which keeps pixel art sharp
+}
+
+// key returns a string identifying the options, used to name cached files.
+// The default options return an empty key so older cache entries still hit
+func (o ImageFit) key() string {
+	key := ""
+	if o.Fit != "" && o.Fit != "stretch" {
+		key += fmt.Sprintf("_%s_%g_%g", o.Fit, o.FocusX, o.FocusY)
+	}
+	if !o.Crop.Empty() {
+		key += fmt.Sprintf("_crop%d,%d,%d,%d", o.Crop.Min.X, o.Crop.Min.Y, o.Crop.Dx(), o.Crop.Dy())
+	}
+	if o.Nearest {
+		key += "_nearest"
+	}
+	return key
+}
+
+// FitImage scales img into a w by h box. Parts of the box the image
+// doesn't cover are left transparent
+func FitImage(img image.Image, w int, h int, o ImageFit) image.Image {
+	if !o.Crop.Empty() {
+		crop := o.Crop.Add(img.Bounds().Min).Intersect(img.Bounds())
+		if !crop.Empty() {
+			cropped := image.NewRGBA(image.Rect(0, 0, crop.Dx(), crop.Dy()))
+			draw.Draw(cropped, cropped.Bounds(), img, crop.Min, draw.Src)
+			img = cropped
+		}
+	}
+
+	filter := resize.Lanczos3
+	if o.Nearest {
+		filter = resize.NearestNeighbor
+	}
+
+	src := img.Bounds()
+	if src.Empty() || w <= 0 || h <= 0 {
+		return img
+	}
+
+	// scale factor for each fit
+	sx, sy := float64(w)/float64(src.Dx()), float64(h)/float64(src.Dy())
+	switch o.Fit {
+	case "contain":
+		sx = math.Min(sx, sy)
+		sy = sx
+	case "cover":
+		sx = math.Max(sx, sy)
+		sy = sx
+	case "none":
+		sx, sy = 1, 1
+	default:
+		return resize.Resize(uint(w), uint(h), img, filter)
+	}
+
+	scaled := img
+	sw := int(math.Round(float64(src.Dx()) * sx))
+	sh := int(math.Round(float64(src.Dy()) * sy))
+	if sw < 1 {
+		sw = 1
+	}
+	if sh < 1 {
+		sh = 1
+	}
+	if sw != src.Dx() || sh != src.Dy() {
+		scaled = resize.Resize(uint(sw), uint(sh), img, filter)
+	}
+
+	// place the scaled image in the box around the focus point, cropping
+	// whatever hangs over the edges
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	at := image.Pt(
+		int(math.Round(float64(w-sw)*o.FocusX)),
+		int(math.Round(float64(h-sh)*o.FocusY)),
+	)
+	draw.Draw(out, scaled.Bounds().Sub(scaled.Bounds().Min).Add(at), scaled, scaled.Bounds().Min, draw.Src)
+	return out
+}
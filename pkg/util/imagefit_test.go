@@ -0,0 +1,58 @@
+package util
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// a 40x20 image, red on the left half and blue on the right
+func testImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	draw.Draw(img, image.Rect(0, 0, 20, 20), image.NewUniform(color.RGBA{255, 0, 0, 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(20, 0, 40, 20), image.NewUniform(color.RGBA{0, 0, 255, 255}), image.Point{}, draw.Src)
+	return img
+}
+
+func TestFitImage(t *testing.T) {
+	alpha := func(img image.Image, x, y int) uint32 {
+		_, _, _, a := img.At(x, y).RGBA()
+		return a
+	}
+
+	// contain letterboxes the wide image
+	contain := FitImage(testImage(), 10, 10, ImageFit{Fit: "contain", FocusX: 0.5, FocusY: 0.5, Nearest: true})
+	assert.Equal(t, image.Rect(0, 0, 10, 10), contain.Bounds())
+	assert.Zero(t, alpha(contain, 5, 1))
+	assert.NotZero(t, alpha(contain, 5, 5))
+	assert.Zero(t, alpha(contain, 5, 8))
+
+	// cover fills the box, keeping the left edge when focused there
+	cover := FitImage(testImage(), 10, 10, ImageFit{Fit: "cover", FocusX: 0, FocusY: 0.5, Nearest: true})
+	assert.Equal(t, color.RGBAModel.Convert(cover.At(9, 9)), color.RGBA{255, 0, 0, 255})
+
+	// crop to the blue half, then stretch
+	crop := FitImage(testImage(), 4, 4, ImageFit{Crop: image.Rect(20, 0, 40, 20), Nearest: true})
+	assert.Equal(t, color.RGBAModel.Convert(crop.At(0, 0)), color.RGBA{0, 0, 255, 255})
+}
+
+func TestImageFilterDither(t *testing.T) {
+	gray := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	draw.Draw(gray, gray.Bounds(), image.NewUniform(color.RGBA{128, 128, 128, 255}), image.Point{}, draw.Src)
+
+	out := ImageFilter{Dither: "bw"}.Apply(gray)
+	white := 0
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			r, _, _, _ := out.At(x, y).RGBA()
+			if r > 0x8000 {
+				white++
+			}
+		}
+	}
+	// mid gray dithers to an even mix of black and white
+	assert.Equal(t, 8, white)
+}
@@ -0,0 +1,277 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+)
+
+// PCF table types
+const (
+	pcfAccelerators    = 1 << 1
+	pcfMetrics         = 1 << 2
+	pcfBitmaps         = 1 << 3
+	pcfBdfEncodings    = 1 << 5
+	pcfBdfAccelerators = 1 << 8
+)
+
+// PCF format flags
+const (
+	pcfGlyphPadMask      = 3 << 0
+	pcfByteMask          = 1 << 2
+	pcfBitMask           = 1 << 3
+	pcfScanUnitMask      = 3 << 4
+	pcfCompressedMetrics = 0x100
+)
+
+// pcfReader reads values from a PCF table, honoring its byte order
+type pcfReader struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+	err   error
+}
+
+func (r *pcfReader) need(n int) bool {
+	if r.err == nil && r.pos+n > len(r.data) {
+		r.err = fmt.Errorf("pcf table truncated")
+	}
+	return r.err == nil
+}
+
+func (r *pcfReader) u8() uint8 {
+	if !r.need(1) {
+		return 0
+	}
+	v := r.data[r.pos]
+	r.pos++
+	return v
+}
+
+func (r *pcfReader) i16() int16 {
+	if !r.need(2) {
+		return 0
+	}
+	v := r.order.Uint16(r.data[r.pos:])
+	r.pos += 2
+	return int16(v)
+}
+
+func (r *pcfReader) i32() int32 {
+	if !r.need(4) {
+		return 0
+	}
+	v := r.order.Uint32(r.data[r.pos:])
+	r.pos += 4
+	return int32(v)
+}
+
+// pcfMetric the glyph metrics shared by the metrics and accelerator tables
+type pcfMetric struct {
+	left, right, width, ascent, descent int
+}
+
+func (r *pcfReader) metric(compressed bool) pcfMetric {
+	if compressed {
+		return pcfMetric{
+			left:    int(r.u8()) - 0x80,
+			right:   int(r.u8()) - 0x80,
+			width:   int(r.u8()) - 0x80,
+			ascent:  int(r.u8()) - 0x80,
+			descent: int(r.u8()) - 0x80,
+		}
+	}
+	m := pcfMetric{
+		left:    int(r.i16()),
+		right:   int(r.i16()),
+		width:   int(r.i16()),
+		ascent:  int(r.i16()),
+		descent: int(r.i16()),
+	}
+	r.i16() // attributes
+	return m
+}
+
+// ParsePCF parses a font in the X11 Portable Compiled Format. Gzipped fonts
+// (.pcf.gz), as commonly shipped by distributions, are also accepted
+func ParsePCF(data []byte) (*BitmapFont, error) {
+	if len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) < 8 || string(data[:4]) != "\x01fcp" {
+		return nil, fmt.Errorf("not a pcf font")
+	}
+
+	// table of contents, always little endian
+	tables := make(map[int32]*pcfReader)
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	for i := 0; i < count; i++ {
+		entry := 8 + i*16
+		if entry+16 > len(data) {
+			return nil, fmt.Errorf("pcf table of contents truncated")
+		}
+		typ := int32(binary.LittleEndian.Uint32(data[entry:]))
+		size := int(binary.LittleEndian.Uint32(data[entry+8:]))
+		offset := int(binary.LittleEndian.Uint32(data[entry+12:]))
+		if offset < 0 || size < 4 || offset+size > len(data) {
+			return nil, fmt.Errorf("pcf table %d out of range", typ)
+		}
+		tables[typ] = &pcfReader{data: data[offset : offset+size], order: binary.LittleEndian}
+	}
+
+	for _, typ := range []int32{pcfMetrics, pcfBitmaps, pcfBdfEncodings} {
+		if tables[typ] == nil {
+			return nil, fmt.Errorf("pcf font missing table %d", typ)
+		}
+	}
+
+	f := newBitmapFont()
+
+	// font wide ascent and descent
+	accel := tables[pcfBdfAccelerators]
+	if accel == nil {
+		accel = tables[pcfAccelerators]
+	}
+	if accel != nil {
+		pcfFormat(accel)
+		accel.pos += 8 // flags and padding
+		f.ascent = int(accel.i32())
+		f.descent = int(accel.i32())
+		if accel.err != nil {
+			return nil, accel.err
+		}
+	}
+
+	// per glyph metrics
+	metricsTable := tables[pcfMetrics]
+	format := pcfFormat(metricsTable)
+	var metrics []pcfMetric
+	compressed := format&pcfCompressedMetrics != 0
+	n := 0
+	if compressed {
+		n = int(metricsTable.i16())
+	} else {
+		n = int(metricsTable.i32())
+	}
+	for i := 0; i < n && metricsTable.err == nil; i++ {
+		metrics = append(metrics, metricsTable.metric(compressed))
+	}
+	if metricsTable.err != nil {
+		return nil, metricsTable.err
+	}
+
+	// glyph bitmaps
+	glyphs, err := pcfGlyphs(tables[pcfBitmaps], metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	// map character codes to glyphs
+	enc := tables[pcfBdfEncodings]
+	pcfFormat(enc)
+	minByte2 := int(enc.i16())
+	maxByte2 := int(enc.i16())
+	minByte1 := int(enc.i16())
+	maxByte1 := int(enc.i16())
+	defaultChar := int(enc.i16())
+	for b1 := minByte1; b1 <= maxByte1; b1++ {
+		for b2 := minByte2; b2 <= maxByte2; b2++ {
+			index := int(uint16(enc.i16()))
+			if enc.err != nil {
+				return nil, enc.err
+			}
+			if index == 0xffff || index >= len(glyphs) {
+				continue
+			}
+			f.glyphs[rune(b1<<8|b2)] = glyphs[index]
+		}
+	}
+	f.defaultChar = rune(defaultChar)
+
+	f.finish()
+	return f, nil
+}
+
+// pcfFormat reads a table's format word and sets its byte order
+func pcfFormat(r *pcfReader) int32 {
+	format := r.i32()
+	if format&pcfByteMask != 0 {
+		r.order = binary.BigEndian
+	}
+	return format
+}
+
+// pcfGlyphs decodes the bitmaps table into one glyph per metric
+func pcfGlyphs(r *pcfReader, metrics []pcfMetric) ([]*bitmapGlyph, error) {
+	format := pcfFormat(r)
+	count := int(r.i32())
+	if count != len(metrics) {
+		return nil, fmt.Errorf("pcf has %d bitmaps for %d metrics", count, len(metrics))
+	}
+	offsets := make([]int, count)
+	for i := range offsets {
+		offsets[i] = int(r.i32())
+	}
+	var sizes [4]int
+	for i := range sizes {
+		sizes[i] = int(r.i32())
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	pad := 1 << (format & pcfGlyphPadMask)
+	scanUnit := 1 << ((format & pcfScanUnitMask) >> 4)
+	msbBit := format&pcfBitMask != 0
+	msbByte := format&pcfByteMask != 0
+	if !r.need(sizes[format&pcfGlyphPadMask]) {
+		return nil, r.err
+	}
+	bits := r.data[r.pos : r.pos+sizes[format&pcfGlyphPadMask]]
+
+	glyphs := make([]*bitmapGlyph, count)
+	for i, m := range metrics {
+		w, h := m.right-m.left, m.ascent+m.descent
+		if w < 0 || h < 0 {
+			return nil, fmt.Errorf("pcf glyph %d has bad metrics", i)
+		}
+		stride := (w + pad*8 - 1) / (pad * 8) * pad
+		mask := image.NewAlpha(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				byteIndex := offsets[i] + y*stride + x/8
+				// bytes are swapped within each scan unit when byte and bit order differ
+				if scanUnit > 1 && msbByte != msbBit {
+					unit := byteIndex / scanUnit * scanUnit
+					byteIndex = unit + scanUnit - 1 - (byteIndex - unit)
+				}
+				if byteIndex >= len(bits) {
+					return nil, fmt.Errorf("pcf glyph %d bitmap out of range", i)
+				}
+				bit := uint(x % 8)
+				if msbBit {
+					bit = 7 - bit
+				}
+				if bits[byteIndex]&(1<<bit) != 0 {
+					mask.Pix[y*mask.Stride+x] = 0xff
+				}
+			}
+		}
+		glyphs[i] = &bitmapGlyph{
+			mask:    mask,
+			offset:  image.Pt(m.left, -m.ascent),
+			advance: m.width,
+		}
+	}
+	return glyphs, nil
+}
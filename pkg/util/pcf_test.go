@@ -0,0 +1,89 @@
+package util
+
+import (
+	"encoding/binary"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// the testdata fonts hold the same two glyphs, "A" and a two byte "Ł" that
+// goes below the baseline, with "A" as the default character. They differ
+// in how they're stored:
+//
+//	test.pcf          big endian, most significant bit first, rows padded to 4 bytes
+//	test.pcf.gz       test.pcf gzipped
+//	test-lsb.pcf      little endian, least significant bit first, rows padded to 1 byte
+//	test-swapped.pcf  big endian with least significant bit first in 4 byte scan units
+func TestParsePCF(t *testing.T) {
+	for _, name := range []string{"test.pcf", "test.pcf.gz", "test-lsb.pcf", "test-swapped.pcf"} {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", name))
+			require.NoError(t, err)
+			face, err := ParsePCF(data)
+			require.NoError(t, err)
+
+			assert.True(t, face.HasGlyph('A'))
+			assert.True(t, face.HasGlyph('Ł'))
+			assert.False(t, face.HasGlyph('B'))
+			assert.Equal(t, fixed.I(5), face.Metrics().Ascent)
+			assert.Equal(t, fixed.I(6), face.Metrics().Height)
+
+			advance, ok := face.GlyphAdvance('Ł')
+			assert.True(t, ok)
+			assert.Equal(t, fixed.I(6), advance)
+			// missing characters fall back to the default
+			advance, ok = face.GlyphAdvance('B')
+			assert.True(t, ok)
+			assert.Equal(t, fixed.I(4), advance)
+
+			dst := image.NewGray(image.Rect(0, 0, 10, 6))
+			d := font.Drawer{Dst: dst, Src: image.White, Face: face, Dot: fixed.P(0, 5)}
+			d.DrawString("AŁ")
+
+			want := []string{
+				".#........",
+				"#.#.......",
+				"###.......",
+				"#.#.#...#.",
+				"#.#.......",
+				"....#####.",
+			}
+			for y, row := range want {
+				for x, ch := range row {
+					v := dst.GrayAt(x, y).Y
+					if ch == '#' {
+						assert.Equal(t, uint8(0xff), v, "pixel %d,%d", x, y)
+					} else {
+						assert.Equal(t, uint8(0), v, "pixel %d,%d", x, y)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParsePCFInvalid(t *testing.T) {
+	_, err := ParsePCF([]byte("not a font"))
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "test.pcf"))
+	require.NoError(t, err)
+
+	// cut off part way through the tables
+	_, err = ParsePCF(data[:len(data)-10])
+	assert.Error(t, err)
+
+	// the metrics table, second in the table of contents, says it's too
+	// short to hold the metrics it counts
+	short := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(short[8+16+8:], 12)
+	_, err = ParsePCF(short)
+	assert.EqualError(t, err, "pcf table truncated")
+}
@@ -0,0 +1,488 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QRLevel how much of a QR code can be damaged and still be read
+type QRLevel int
+
+const (
+	QRLow      QRLevel = iota // about 7%
+	QRMedium                  // about 15%
+	QRQuartile                // about 25%
+	QRHigh                    // about 30%
+)
+
+// ParseQRLevel parses an error correction level given as L, M, Q or H
+func ParseQRLevel(s string) (QRLevel, error) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return QRLow, nil
+	case "M", "":
+		return QRMedium, nil
+	case "Q":
+		return QRQuartile, nil
+	case "H":
+		return QRHigh, nil
+	}
+	return QRMedium, fmt.Errorf("invalid qr level '%s'", s)
+}
+
+// formatBits the level's value in the format information
+func (l QRLevel) formatBits() int {
+	return [...]int{1, 0, 3, 2}[l]
+}
+
+// error correction codewords per block and number of blocks, indexed by
+// level then version
+var (
+	qrECCPerBlock = [4][41]int{
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	qrBlocks = [4][41]int{
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+)
+
+// QRCode a QR code symbol, a square grid of dark and light modules. The
+// quiet zone around the symbol isn't included
+type QRCode struct {
+	Size     int
+	version  int
+	level    QRLevel
+	modules  [][]bool
+	function [][]bool // modules that are part of the fixed patterns
+}
+
+// Dark reports whether the module at x, y is dark
+func (q *QRCode) Dark(x, y int) bool {
+	return q.modules[y][x]
+}
+
+// EncodeQR encodes data in byte mode, using the smallest version it fits
+// in. The error correction level is raised when that doesn't need a
+// bigger symbol
+func EncodeQR(data []byte, level QRLevel) (*QRCode, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if qrDataBits(v, len(data)) <= qrDataCodewords(v, level)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%d bytes is too long for a qr code", len(data))
+	}
+	for l := level + 1; l <= QRHigh; l++ {
+		if qrDataBits(version, len(data)) <= qrDataCodewords(version, l)*8 {
+			level = l
+		}
+	}
+
+	// mode, length and data, then terminate and pad to capacity
+	var bits qrBits
+	bits.append(0x4, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version, level) * 8
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		codewords[i>>3] |= byte(bit) << (7 - i&7)
+	}
+
+	q := &QRCode{Size: version*4 + 17, version: version, level: level}
+	q.modules = make([][]bool, q.Size)
+	q.function = make([][]bool, q.Size)
+	for y := range q.modules {
+		q.modules[y] = make([]bool, q.Size)
+		q.function[y] = make([]bool, q.Size)
+	}
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.addECCAndInterleave(codewords))
+
+	// pick the mask that's easiest to scan
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // masks undo themselves
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+	return q, nil
+}
+
+// qrBits a buffer of bits, one per element
+type qrBits []int
+
+func (b *qrBits) append(value int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1)
+	}
+}
+
+// qrCountBits the width of the byte mode length field
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrDataBits how many bits n bytes take up in byte mode
+func qrDataBits(version int, n int) int {
+	return 4 + qrCountBits(version) + 8*n
+}
+
+// qrRawModules how many modules are left for data and error correction
+// once the function patterns are drawn
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		n -= (25*align-10)*align - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+func qrDataCodewords(version int, level QRLevel) int {
+	return qrRawModules(version)/8 - qrECCPerBlock[level][version]*qrBlocks[level][version]
+}
+
+func (q *QRCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// alignmentPositions the centers of the alignment patterns along each axis
+func (q *QRCode) alignmentPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+	count := q.version/7 + 2
+	step := (q.version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := []int{6}
+	for pos := q.Size - 7; len(positions) < count; pos -= step {
+		positions = append(positions[:1], append([]int{pos}, positions[1:]...)...)
+	}
+	return positions
+}
+
+func (q *QRCode) drawFunctionPatterns() {
+	for i := 0; i < q.Size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	// finder patterns and their separators
+	for _, c := range [][2]int{{3, 3}, {q.Size - 4, 3}, {3, q.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := c[0]+dx, c[1]+dy
+				if x >= 0 && x < q.Size && y >= 0 && y < q.Size {
+					dist := max(abs(dx), abs(dy))
+					q.setFunction(x, y, dist != 2 && dist != 4)
+				}
+			}
+		}
+	}
+
+	// alignment patterns, except where they'd overlap the finders
+	positions := q.alignmentPositions()
+	last := len(positions) - 1
+	for i, py := range positions {
+		for j, px := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(px+dx, py+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// reserve the format areas, and draw the version for bigger symbols
+	q.drawFormatBits(0)
+	if q.version >= 7 {
+		rem := q.version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+		}
+		bits := q.version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := (bits>>i)&1 != 0
+			a, b := q.Size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the level and mask information
+func (q *QRCode) drawFormatBits(mask int) {
+	data := q.level.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.Size-15+i, bit(i))
+	}
+	q.setFunction(8, q.Size-8, true) // always dark
+}
+
+// addECCAndInterleave splits the data into blocks, adds error correction
+// to each and interleaves the results
+func (q *QRCode) addECCAndInterleave(data []byte) []byte {
+	blocks := qrBlocks[q.level][q.version]
+	eccLen := qrECCPerBlock[q.level][q.version]
+	raw := qrRawModules(q.version) / 8
+	shortBlocks := blocks - raw%blocks
+	shortLen := raw / blocks
+
+	divisor := rsDivisor(eccLen)
+	var all [][]byte
+	for i, k := 0, 0; i < blocks; i++ {
+		n := shortLen - eccLen
+		if i >= shortBlocks {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0)
+		}
+		all = append(all, append(block, ecc...))
+	}
+
+	var out []byte
+	for i := range all[0] {
+		for j, block := range all {
+			// skip the padding byte of short blocks
+			if i != shortLen-eccLen || j >= shortBlocks {
+				out = append(out, block[i])
+			}
+		}
+	}
+	return out
+}
+
+// drawCodewords fills the data area in the zigzag order
+func (q *QRCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.Size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(data)*8 {
+					q.modules[y][x] = (data[i>>3]>>(7-i&7))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.function[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the symbol is to scan, lower is better
+func (q *QRCode) penalty() int {
+	penalty := 0
+	finder := []bool{true, false, true, true, true, false, true}
+	line := func(at func(i int) bool) {
+		run := 1
+		for i := 1; i <= q.Size; i++ {
+			if i < q.Size && at(i) == at(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				penalty += 3 + run - 5
+			}
+			run = 1
+		}
+		// finder-like patterns with four light modules on either side
+		for i := 0; i+7 <= q.Size; i++ {
+			match := true
+			for k, dark := range finder {
+				if at(i+k) != dark {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			light := func(from, to int) bool {
+				for k := from; k < to; k++ {
+					if k >= 0 && k < q.Size && at(k) {
+						return false
+					}
+				}
+				return true
+			}
+			if light(i-4, i) || light(i+7, i+11) {
+				penalty += 40
+			}
+		}
+	}
+	for y := 0; y < q.Size; y++ {
+		line(func(x int) bool { return q.modules[y][x] })
+	}
+	for x := 0; x < q.Size; x++ {
+		line(func(y int) bool { return q.modules[y][x] })
+	}
+
+	dark := 0
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.Size && y+1 < q.Size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := q.Size * q.Size
+	k := (abs(dark*20-total*10)+total-1)/total - 1
+	return penalty + k*10
+}
+
+// rsMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func rsMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int((y>>i)&1) * int(x)
+	}
+	return byte(z)
+}
+
+// rsDivisor the generator polynomial for the given number of error
+// correction codewords, highest coefficient dropped
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = rsMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = rsMultiply(root, 2)
+	}
+	return result
+}
+
+// rsRemainder the error correction codewords for data
+func rsRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, d := range divisor {
+			result[i] ^= rsMultiply(d, factor)
+		}
+	}
+	return result
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,56 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// "HELLO WORLD" as 1-M from the spec's worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	assert.Equal(t, ecc, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestEncodeQR(t *testing.T) {
+	q, err := EncodeQR([]byte("https://example.com"), QRLow)
+	require.NoError(t, err)
+	assert.Equal(t, 25, q.Size) // version 2
+
+	// finder pattern corners and the always dark module
+	for _, p := range [][2]int{{0, 0}, {6, 6}, {q.Size - 1, 0}, {0, q.Size - 1}, {8, q.Size - 8}} {
+		assert.True(t, q.Dark(p[0], p[1]))
+	}
+	assert.False(t, q.Dark(7, 7))
+
+	// the format bits read back the same from both copies
+	var first, second int
+	for i := 0; i <= 5; i++ {
+		first |= b2i(q.Dark(8, i)) << i
+	}
+	first |= b2i(q.Dark(8, 7))<<6 | b2i(q.Dark(8, 8))<<7 | b2i(q.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		first |= b2i(q.Dark(14-i, 8)) << i
+	}
+	for i := 0; i < 8; i++ {
+		second |= b2i(q.Dark(q.Size-1-i, 8)) << i
+	}
+	for i := 8; i < 15; i++ {
+		second |= b2i(q.Dark(8, q.Size-15+i)) << i
+	}
+	assert.Equal(t, first, second)
+	// level is boosted as far as it fits without growing
+	assert.Equal(t, QRQuartile.formatBits(), (first^0x5412)>>13)
+
+	_, err = EncodeQR(make([]byte, 3000), QRLow)
+	assert.Error(t, err)
+}
+
+func b2i(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
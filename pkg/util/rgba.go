@@ -2,20 +2,22 @@ package util
 
 import (
 	"encoding/xml"
-	"fmt"
 	"image/color"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
 )
 
+// RGBA wraps color.RGBA for unmarshalling from XML, accepting any color
+// colors.Parse does
 type RGBA struct {
 	color.RGBA
 }
 
 func (c *RGBA) UnmarshalXMLAttr(attr xml.Attr) error {
-	var r, g, b, a uint8
-	_, err := fmt.Sscanf(attr.Value, "#%02x%02x%02x%02x", &r, &g, &b, &a)
+	parsed, err := colors.Parse(attr.Value)
 	if err != nil {
 		return err
 	}
-	c.RGBA = color.RGBA{r, g, b, a}
+	c.RGBA = color.RGBAModel.Convert(parsed).(color.RGBA)
 	return nil
 }
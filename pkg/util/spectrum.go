@@ -0,0 +1,89 @@
+package util
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// FFT an in place radix-2 fast Fourier transform. len(x) must be a power
+// of two
+func FFT(x []complex128) {
+	n := len(x)
+	// put the input in bit reversed order
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a, b := x[start+k], x[start+k+size/2]*w
+				x[start+k], x[start+k+size/2] = a+b, a-b
+				w *= step
+			}
+		}
+	}
+}
+
+// Spectrum the amplitude of each frequency in samples, which should be
+// a power of two long. Bin i is i*sampleRate/len(samples) Hz, and a full
+// scale sine comes out at about 1
+func Spectrum(samples []float64) []float64 {
+	n := len(samples)
+	x := make([]complex128, n)
+	for i, s := range samples {
+		// a Hann window, so the ends of the window don't smear across bins
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+		x[i] = complex(s*hann, 0)
+	}
+	FFT(x)
+	out := make([]float64, n/2)
+	for i := range out {
+		// the window halves the amplitude, and half of it is in the
+		// negative frequencies
+		out[i] = cmplx.Abs(x[i]) * 4 / float64(n)
+	}
+	return out
+}
+
+// SpectrumBands groups a Spectrum into bands spaced evenly on a log scale
+// from minHz to maxHz, the way we hear pitch, taking the loudest bin in
+// each. Bands narrower than a bin read the bin they fall in
+func SpectrumBands(spectrum []float64, sampleRate int, bands int, minHz float64, maxHz float64) []float64 {
+	out := make([]float64, bands)
+	if len(spectrum) == 0 {
+		return out
+	}
+	binHz := float64(sampleRate) / float64(len(spectrum)*2)
+	ratio := math.Pow(maxHz/minHz, 1/float64(bands))
+	for b := range out {
+		lo := minHz * math.Pow(ratio, float64(b))
+		from := int(math.Round(lo / binHz))
+		to := int(math.Round(lo * ratio / binHz))
+		if to <= from {
+			to = from + 1
+		}
+		for i := from; i < to && i < len(spectrum); i++ {
+			out[b] = math.Max(out[b], spectrum[i])
+		}
+	}
+	return out
+}
+
+// Decibels converts an amplitude to a level from 0 to 1, 0 at floor dB
+// (e.g. -60) and below, 1 at 0 dB and above
+func Decibels(amplitude float64, floor float64) float64 {
+	if amplitude <= 0 {
+		return 0
+	}
+	db := 20 * math.Log10(amplitude)
+	return math.Max(0, math.Min(1, 1-db/floor))
+}
@@ -3,6 +3,9 @@ package util
 type UtilConfig struct {
 	CacheDir string
 	FontDir  string
+	// FontFallbacks fonts tried, in order, for glyphs a font doesn't have
+	FontFallbacks []string
+	ImageDir      string
 }
 
 var (
@@ -10,6 +10,7 @@ type BaseView struct {
 	dataRefresh     *time.Ticker
 	templateRefresh *time.Ticker
 	stopChan        chan struct{}
+	theme           string
 }
 
 func (v *BaseView) Init() {
@@ -25,7 +26,11 @@ func (v *BaseView) SetTemplateValue(t compCommon.Template) {
 	*v.template = t
 }
 
+// SetTemplate points the view at another template, e.g. a playlist
+// switching to its next view's
 func (v *BaseView) SetTemplate(t *compCommon.Template) {
+	compCommon.RenderMu.Lock()
+	defer compCommon.RenderMu.Unlock()
 	v.template = t
 }
 
@@ -37,4 +42,13 @@ func (v *BaseView) TemplateString() string {
 	return ""
 }
 
+// SetTheme picks the theme the view renders with, "" for the active one
+func (v *BaseView) SetTheme(name string) {
+	v.theme = name
+}
+
+func (v *BaseView) ThemeName() string {
+	return v.theme
+}
+
 func (v *BaseView) Stop() {}
@@ -0,0 +1,523 @@
+package common
+
+import (
+	"fmt"
+	"html/template"
+	"image/color"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
+	compCommon "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+)
+
+// templateFuncs the functions available to every template. Functions that
+// take a value to work on take it last, so they can be piped into, e.g.
+// {{ .Name | truncate 10 | upper }}
+//
+// Numbers
+//
+//	fixed 2 3.14159          "3.14"
+//	number 0 1234567         "1,234,567", with thousands separators
+//	percent 1 0.256          "25.6%"
+//	signed 3                 "+3"
+//	ordinal 22               "22nd"
+//	CardinalToOrdinal 3      "3rd", kept for older templates
+//
+// Text
+//
+//	padLeft 3 "7"            "  7"
+//	padRight 3 "7"           "7  "
+//	padZero 2 7              "07"
+//	truncate 3 "Eagles"      "Eag"
+//	ellipsis 5 "Eagles"      "Ea..."
+//	upper, lower, title, trim
+//
+// Time, accepting a time.Time, RFC3339 string or unix seconds
+//
+//	now                      when the template was rendered, also .Now
+//	formatTime "3:04PM" t    using Go's reference time layout
+//	inZone "America/New_York" t
+//	timeAgo t                "5m ago", or "in 2h" for times to come
+//	parseTime "2006-01-02" "2024-09-08"
+//
+// Math, returning float64s. int converts back to an int
+//
+//	add, sub, mul, div, mod, min, max, abs, floor, ceil, int
+//	round 1 2.345            2.3
+//	clamp 0 100 120          100
+//
+// Colors, anything colors.Parse accepts, e.g. #F80, red or hsl(30, 100%, 50%),
+// always returned as #RRGGBBAA
+//
+//	lighten 0.2 c            20% of the way to white
+//	darken 0.2 c             20% of the way to black
+//	mix 0.5 a b              halfway from a to b, alpha too
+//	alpha 0.5 c              set the alpha
+//	contrast c               black or white, whichever reads better on c
+//	threshold v c0 t1 c1 t2 c2 ...
+//	                         c0 below t1, c1 from t1 up to t2 and so on
+//
+// Lists, of anything
+//
+//	first, last, reverse
+//	take 3 list              the first 3
+//	skip 3 list              all but the first 3
+//	sortBy "Points" list     ascending by a field or map key
+//	sortDesc "Points" list   descending
+//
+// Other
+//
+//	default "N/A" v          v, unless it's nil or an empty string or list
+//	NilOrDefault             "N/A"
+var templateFuncs = template.FuncMap{
+	"NilOrDefault":      func() string { return "N/A" },
+	"CardinalToOrdinal": ordinal,
+
+	"fixed":   fixed,
+	"number":  number,
+	"percent": func(decimals int, v interface{}) string { return fixed(decimals, toFloat(v)*100) + "%" },
+	"signed":  signed,
+	"ordinal": ordinal,
+
+	"padLeft":  func(n int, v interface{}) string { return pad(n, v, true) },
+	"padRight": func(n int, v interface{}) string { return pad(n, v, false) },
+	"padZero": func(n int, v interface{}) string {
+		return fmt.Sprintf("%0*d", n, int(math.Round(toFloat(v))))
+	},
+	"truncate": truncate,
+	"ellipsis": ellipsis,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"title":    title,
+	"trim":     strings.TrimSpace,
+
+	"now":        func() time.Time { return Now() },
+	"formatTime": func(layout string, t interface{}) string { return toTime(t).Format(layout) },
+	"inZone":     inZone,
+	"timeAgo":    func(t interface{}) string { return timeAgo(toTime(t), Now()) },
+	"parseTime": func(layout string, value string) (time.Time, error) {
+		return time.ParseInLocation(layout, value, time.Local)
+	},
+
+	"add":   func(a, b interface{}) float64 { return toFloat(a) + toFloat(b) },
+	"sub":   func(a, b interface{}) float64 { return toFloat(a) - toFloat(b) },
+	"mul":   func(a, b interface{}) float64 { return toFloat(a) * toFloat(b) },
+	"div":   div,
+	"mod":   func(a, b interface{}) float64 { return math.Mod(toFloat(a), toFloat(b)) },
+	"min":   func(a, b interface{}) float64 { return math.Min(toFloat(a), toFloat(b)) },
+	"max":   func(a, b interface{}) float64 { return math.Max(toFloat(a), toFloat(b)) },
+	"abs":   func(v interface{}) float64 { return math.Abs(toFloat(v)) },
+	"floor": func(v interface{}) float64 { return math.Floor(toFloat(v)) },
+	"ceil":  func(v interface{}) float64 { return math.Ceil(toFloat(v)) },
+	"round": func(decimals int, v interface{}) float64 {
+		scale := math.Pow(10, float64(decimals))
+		return math.Round(toFloat(v)*scale) / scale
+	},
+	"clamp": func(lo, hi, v interface{}) float64 {
+		return math.Max(toFloat(lo), math.Min(toFloat(hi), toFloat(v)))
+	},
+	"int": func(v interface{}) int { return int(toFloat(v)) },
+
+	"lighten":   func(amount float64, c string) string { return shade(amount, c, color.NRGBA{255, 255, 255, 0}) },
+	"darken":    func(amount float64, c string) string { return shade(amount, c, color.NRGBA{0, 0, 0, 0}) },
+	"mix":       mixColors,
+	"alpha":     alpha,
+	"contrast":  contrast,
+	"threshold": threshold,
+
+	"first":   first,
+	"last":    last,
+	"reverse": reverse,
+	"take":    take,
+	"skip":    skip,
+	"sortBy": func(key string, list interface{}) (interface{}, error) {
+		return sortBy(key, list, false)
+	},
+	"sortDesc": func(key string, list interface{}) (interface{}, error) {
+		return sortBy(key, list, true)
+	},
+
+	"default": defaultValue,
+}
+
+// Now the time templates are rendered at, swapped out by tests so they
+// render the same every run
+var Now = time.Now
+
+// timeFuncs binds now and timeAgo to the time a template is rendered at, so
+// every use in one refresh agrees
+func timeFuncs(now time.Time) template.FuncMap {
+	return template.FuncMap{
+		"now":     func() time.Time { return now },
+		"timeAgo": func(t interface{}) string { return timeAgo(toTime(t), now) },
+	}
+}
+
+// toFloat converts any number, or a string holding one, to a float64
+func toFloat(v interface{}) float64 {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+		return f
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1
+		}
+	}
+	return 0
+}
+
+func toString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// toTime converts a time, RFC3339 string or unix seconds to a time
+func toTime(v interface{}) time.Time {
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case *time.Time:
+		return *t
+	case string:
+		if parsed, err := time.Parse(time.RFC3339, t); err == nil {
+			return parsed
+		}
+	}
+	return time.Unix(int64(toFloat(v)), 0)
+}
+
+func fixed(decimals int, v interface{}) string {
+	return strconv.FormatFloat(toFloat(v), 'f', decimals, 64)
+}
+
+// number formats with thousands separators
+func number(decimals int, v interface{}) string {
+	s := fixed(decimals, v)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i:]
+	}
+	var out strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(r)
+	}
+	return sign + out.String() + frac
+}
+
+func signed(v interface{}) string {
+	f := toFloat(v)
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if f > 0 {
+		return "+" + s
+	}
+	return s
+}
+
+func ordinal(v interface{}) string {
+	n := int(toFloat(v))
+	abs := n
+	if abs < 0 {
+		abs = -abs
+	}
+	suffix := "th"
+	if abs%100 < 11 || abs%100 > 13 {
+		switch abs % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func pad(n int, v interface{}, left bool) string {
+	s := toString(v)
+	fill := n - utf8.RuneCountInString(s)
+	if fill <= 0 {
+		return s
+	}
+	if left {
+		return strings.Repeat(" ", fill) + s
+	}
+	return s + strings.Repeat(" ", fill)
+}
+
+func truncate(n int, v interface{}) string {
+	runes := []rune(toString(v))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n])
+}
+
+func ellipsis(n int, v interface{}) string {
+	runes := []rune(toString(v))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	if n <= 3 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+func title(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r, size := utf8.DecodeRuneInString(w)
+		words[i] = strings.ToUpper(string(r)) + strings.ToLower(w[size:])
+	}
+	return strings.Join(words, " ")
+}
+
+func inZone(zone string, t interface{}) (time.Time, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return toTime(t).In(loc), nil
+}
+
+// timeAgo describes t relative to now in its largest whole unit
+func timeAgo(t time.Time, now time.Time) string {
+	d := now.Sub(t)
+	future := d < 0
+	if future {
+		d = -d
+	}
+	var s string
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		s = fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		s = fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 7*24*time.Hour:
+		s = fmt.Sprintf("%dd", int(d.Hours()/24))
+	default:
+		s = fmt.Sprintf("%dw", int(d.Hours()/24/7))
+	}
+	if future {
+		return "in " + s
+	}
+	return s + " ago"
+}
+
+func div(a, b interface{}) (float64, error) {
+	if toFloat(b) == 0 {
+		return 0, fmt.Errorf("division by zero")
+	}
+	return toFloat(a) / toFloat(b), nil
+}
+
+func formatColor(c color.NRGBA) string {
+	return colors.Hex(c)
+}
+
+func parseColor(s string) color.NRGBA {
+	return compCommon.ParseBgColor(s).(color.NRGBA)
+}
+
+// mixColors blends from a towards b, alpha included
+func mixColors(amount float64, a string, b string) string {
+	ca, cb := parseColor(a), parseColor(b)
+	amount = math.Max(0, math.Min(1, amount))
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*amount))
+	}
+	return formatColor(color.NRGBA{lerp(ca.R, cb.R), lerp(ca.G, cb.G), lerp(ca.B, cb.B), lerp(ca.A, cb.A)})
+}
+
+// shade moves c towards to, keeping c's alpha
+func shade(amount float64, c string, to color.NRGBA) string {
+	to.A = parseColor(c).A
+	return mixColors(amount, c, formatColor(to))
+}
+
+func alpha(a float64, c string) string {
+	nc := parseColor(c)
+	nc.A = uint8(math.Round(math.Max(0, math.Min(1, a)) * 255))
+	return formatColor(nc)
+}
+
+// contrast picks black or white text for the background c
+func contrast(c string) string {
+	nc := parseColor(c)
+	luminance := 0.299*float64(nc.R) + 0.587*float64(nc.G) + 0.114*float64(nc.B)
+	if luminance > 128 {
+		return "#000000FF"
+	}
+	return "#FFFFFFFF"
+}
+
+func threshold(v interface{}, below interface{}, steps ...interface{}) (interface{}, error) {
+	if len(steps)%2 != 0 {
+		return nil, fmt.Errorf("threshold needs a value for each step")
+	}
+	f := toFloat(v)
+	result := below
+	for i := 0; i < len(steps); i += 2 {
+		if f >= toFloat(steps[i]) {
+			result = steps[i+1]
+		}
+	}
+	return result, nil
+}
+
+func listValue(list interface{}) (reflect.Value, error) {
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return rv, fmt.Errorf("expected a list, got %T", list)
+	}
+	return rv, nil
+}
+
+func first(list interface{}) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil || rv.Len() == 0 {
+		return nil, err
+	}
+	return rv.Index(0).Interface(), nil
+}
+
+func last(list interface{}) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil || rv.Len() == 0 {
+		return nil, err
+	}
+	return rv.Index(rv.Len() - 1).Interface(), nil
+}
+
+func take(n int, list interface{}) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Slice(0, clampIndex(n, rv.Len())).Interface(), nil
+}
+
+func skip(n int, list interface{}) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Slice(clampIndex(n, rv.Len()), rv.Len()).Interface(), nil
+}
+
+func clampIndex(n int, length int) int {
+	if n < 0 {
+		return 0
+	}
+	if n > length {
+		return length
+	}
+	return n
+}
+
+// copyList copies a list so sorting doesn't touch the view's data
+func copyList(rv reflect.Value) reflect.Value {
+	out := reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), rv.Len(), rv.Len())
+	reflect.Copy(out, rv)
+	return out
+}
+
+func reverse(list interface{}) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil {
+		return nil, err
+	}
+	out := copyList(rv)
+	swap := reflect.Swapper(out.Interface())
+	for i, j := 0, out.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+	return out.Interface(), nil
+}
+
+// field looks up a struct field or map key by name, an empty key giving
+// back the element itself
+func field(v reflect.Value, key string) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if key == "" {
+		return v
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return v.FieldByName(key)
+	case reflect.Map:
+		return v.MapIndex(reflect.ValueOf(key))
+	}
+	return reflect.Value{}
+}
+
+func sortBy(key string, list interface{}, desc bool) (interface{}, error) {
+	rv, err := listValue(list)
+	if err != nil {
+		return nil, err
+	}
+	out := copyList(rv)
+	less := func(i, j int) bool {
+		a, b := field(out.Index(i), key), field(out.Index(j), key)
+		if !a.IsValid() || !b.IsValid() {
+			return false
+		}
+		if a.Kind() == reflect.String {
+			return a.String() < b.String()
+		}
+		return toFloat(a.Interface()) < toFloat(b.Interface())
+	}
+	sort.SliceStable(out.Interface(), func(i, j int) bool {
+		if desc {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+	return out.Interface(), nil
+}
+
+func defaultValue(def interface{}, v interface{}) interface{} {
+	if v == nil {
+		return def
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		if rv.Len() == 0 {
+			return def
+		}
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return def
+		}
+	}
+	return v
+}
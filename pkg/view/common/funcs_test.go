@@ -0,0 +1,61 @@
+package common
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	type player struct {
+		Name   string
+		Points float64
+	}
+	data := map[string]interface{}{
+		"Players": []player{{"A", 3}, {"B", 12.5}, {"C", 7}},
+		"Kickoff": time.Date(2024, 9, 8, 17, 0, 0, 0, time.UTC),
+		"Empty":   "",
+	}
+
+	now := time.Date(2024, 9, 8, 17, 30, 0, 0, time.UTC)
+	for tmpl, want := range map[string]string{
+		`{{ number 2 1234567.891 }}`:                                              "1,234,567.89",
+		`{{ number 0 -1234 }}`:                                                    "-1,234",
+		`{{ percent 1 0.256 }}`:                                                   "25.6%",
+		`{{ signed 3 }} {{ signed -2 }}`:                                          "+3 -2",
+		`{{ ordinal 1 }} {{ ordinal 12 }} {{ ordinal 23 }}`:                       "1st 12th 23rd",
+		`{{ ordinal -1 }} {{ ordinal -12 }} {{ ordinal -22 }} {{ ordinal 0 }}`:    "-1st -12th -22nd 0th",
+		`{{ CardinalToOrdinal 5 }}`:                                               "5th",
+		`{{ "7" | padLeft 3 }}|{{ padZero 2 7 }}`:                                 "  7|07",
+		`{{ "Eagles" | ellipsis 5 }} {{ truncate 3 "Eagles" }}`:                   "Ea... Eag",
+		`{{ title "new york giants" }}`:                                           "New York Giants",
+		`{{ .Kickoff | inZone "America/New_York" | formatTime "3:04PM" }}`:        "1:00PM",
+		`{{ add 1 2 }} {{ div 7 2 }} {{ round 1 2.345 }} {{ clamp 0 100 120 }}`:   "3 3.5 2.3 100",
+		`{{ lighten 0.5 "#000000FF" }} {{ darken 1 "#FF8800" }}`:                  "#808080FF #000000FF",
+		`{{ mix 0.5 "#FF000000" "#0000FFFF" }}`:                                   "#80008080",
+		`{{ lighten 0.5 "#00000080" }} {{ darken 0.5 "#FFFFFF00" }}`:              "#80808080 #80808000",
+		`{{ mix 0.5 "#FF0000FF" "#0000FFFF" }}`:                                   "#800080FF",
+		`{{ contrast "#FFFF00FF" }} {{ contrast "#000080FF" }}`:                   "#000000FF #FFFFFFFF",
+		`{{ threshold 15 "red" 10 "yellow" 20 "green" }}`:                         "yellow",
+		`{{ range sortDesc "Points" .Players | take 2 }}{{ .Name }}{{ end }}`:     "BC",
+		`{{ (first (sortBy "Points" .Players)).Name }}{{ (last .Players).Name }}`: "AC",
+		`{{ range reverse .Players | skip 1 }}{{ .Name }}{{ end }}`:               "BA",
+		`{{ .Empty | default "N/A" }} {{ "x" | default "N/A" }}`:                  "N/A x",
+		`{{ now | formatTime "15:04" }} {{ timeAgo .Kickoff }}`:                   "17:30 30m ago",
+	} {
+		parsed, err := template.New("test").Funcs(templateFuncs).Funcs(timeFuncs(now)).Parse(tmpl)
+		assert.NoError(t, err, tmpl)
+		var buf bytes.Buffer
+		assert.NoError(t, parsed.Execute(&buf, data), tmpl)
+		// the xml decoder undoes html/template's escaping
+		assert.Equal(t, want, html.UnescapeString(buf.String()), tmpl)
+	}
+
+	assert.Equal(t, "5m ago", timeAgo(now.Add(-5*time.Minute), now))
+	assert.Equal(t, "in 2h", timeAgo(now.Add(2*time.Hour+time.Minute), now))
+	assert.Equal(t, "now", timeAgo(now, now))
+}
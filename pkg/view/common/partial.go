@@ -0,0 +1,321 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Partial a named template fragment that can be reused from any view's
+// template with <use name="..." param="value">. The body is a template
+// itself, with each param available as {{ .param }}. <slot/> in the body
+// is replaced by the children of the <use> element, and <slot name="x"/>
+// by the children marked slot="x"
+type Partial struct {
+	Name   string         `json:"name"`
+	Params []PartialParam `json:"params"`
+	Body   string         `json:"body"`
+}
+
+// PartialParam a param a partial accepts
+type PartialParam struct {
+	Name     string `json:"name"`
+	Default  string `json:"default"`
+	Required bool   `json:"required"`
+}
+
+const (
+	partialPrefix = "PT"
+	// maxPartialDepth how deep partials can use other partials, which
+	// also stops a partial from using itself forever
+	maxPartialDepth = 8
+)
+
+// builtinPartials partials shipped with the app, ones in the store take
+// precedence
+var builtinPartials = map[string]Partial{}
+
+// RegisterPartial adds a built in partial
+func RegisterPartial(p Partial) {
+	builtinPartials[p.Name] = p
+}
+
+// Validate checks that the partial has a name and its body parses
+func (p Partial) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("partial name is required")
+	}
+	for _, param := range p.Params {
+		if param.Name == "" {
+			return fmt.Errorf("partial '%s' has a param with no name", p.Name)
+		}
+	}
+	_, err := template.New(p.Name).Funcs(templateFuncs).Parse(templateVars + p.Body)
+	return err
+}
+
+// SavePartial saves a partial to the store
+func SavePartial(p Partial) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	_, err = CommonConfig.Store.SaveItem(partialPrefix, p.Name, data)
+	return err
+}
+
+// GetPartial looks up a partial by name, in the store and then the built
+// in ones
+func GetPartial(name string) (Partial, error) {
+	var p Partial
+	if CommonConfig.Store != nil {
+		data, err := CommonConfig.Store.GetItem(partialPrefix + "-" + name)
+		if err != nil {
+			return p, err
+		}
+		if len(data) > 0 {
+			err = json.Unmarshal(data, &p)
+			return p, err
+		}
+	}
+	p, ok := builtinPartials[name]
+	if !ok {
+		return p, fmt.Errorf("no partial named '%s'", name)
+	}
+	return p, nil
+}
+
+// GetAllPartials retrieves every partial, built in ones included
+func GetAllPartials() ([]Partial, error) {
+	saved := map[string]bool{}
+	var partials []Partial
+	if CommonConfig.Store != nil {
+		datas, err := CommonConfig.Store.GetPrefix(partialPrefix + "-")
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range datas {
+			var p Partial
+			if err := json.Unmarshal(data, &p); err != nil {
+				return nil, err
+			}
+			saved[p.Name] = true
+			partials = append(partials, p)
+		}
+	}
+	for name, p := range builtinPartials {
+		if !saved[name] {
+			partials = append(partials, p)
+		}
+	}
+	return partials, nil
+}
+
+// DeletePartial deletes a partial from the store
+func DeletePartial(name string) error {
+	return CommonConfig.Store.DeleteItem(partialPrefix + "-" + name)
+}
+
+// element an element found in a chunk of xml, by where it sits in it
+type element struct {
+	name       string
+	attrs      []xml.Attr
+	start, end int // offsets of the whole element
+	inner      int // offset just past the start tag
+	innerEnd   int // offset of the end tag
+}
+
+// scanElements finds the elements named name in src, skipping over the
+// insides of ones it finds
+func scanElements(src string, name string) ([]element, error) {
+	dec := xml.NewDecoder(strings.NewReader(src))
+	dec.Strict = false
+	var found []element
+	var current *element
+	depth := 0
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if current == nil && tok.Name.Local == name {
+				current = &element{name: name, attrs: tok.Attr, start: offset, inner: int(dec.InputOffset())}
+				depth = 0
+			} else if current != nil {
+				depth++
+			}
+		case xml.EndElement:
+			if current == nil {
+				continue
+			}
+			if depth > 0 {
+				depth--
+				continue
+			}
+			current.innerEnd = offset
+			current.end = int(dec.InputOffset())
+			if current.innerEnd < current.inner {
+				// self closing, the end tag took up no input
+				current.innerEnd = current.inner
+			}
+			found = append(found, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("unclosed <%s>", name)
+	}
+	return found, nil
+}
+
+func attr(attrs []xml.Attr, name string) (string, bool) {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// splitSlots sorts the children of a <use> element into the slots they
+// fill, the ones without a slot attr going to the default slot
+func splitSlots(inner string) (map[string]string, error) {
+	slots := map[string]string{}
+	dec := xml.NewDecoder(strings.NewReader(inner))
+	dec.Strict = false
+	depth, start, slot := 0, 0, ""
+	for {
+		offset := int(dec.InputOffset())
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				start = offset
+				slot, _ = attr(tok.Attr, "slot")
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				slots[slot] += inner[start:dec.InputOffset()]
+			}
+		default:
+			if depth == 0 {
+				slots[""] += inner[offset:dec.InputOffset()]
+			}
+		}
+	}
+	return slots, nil
+}
+
+// expandPartials replaces each <use> element in src with the partial it
+// names, repeatedly, until none are left. Partials render with theme as
+// their $Theme and now as the time
+func expandPartials(src string, lookup func(string) (Partial, error), theme Theme, now time.Time) (string, error) {
+	for depth := 0; ; depth++ {
+		uses, err := scanElements(src, "use")
+		if err != nil {
+			return "", err
+		}
+		if len(uses) == 0 {
+			return src, nil
+		}
+		if depth == maxPartialDepth {
+			return "", fmt.Errorf("partials nested more than %d deep, is one using itself?", maxPartialDepth)
+		}
+
+		var out strings.Builder
+		last := 0
+		for _, use := range uses {
+			expanded, err := expandUse(src, use, lookup, theme, now)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(src[last:use.start])
+			out.WriteString(expanded)
+			last = use.end
+		}
+		out.WriteString(src[last:])
+		src = out.String()
+	}
+}
+
+// expandUse renders the partial a single <use> element refers to
+func expandUse(src string, use element, lookup func(string) (Partial, error), theme Theme, now time.Time) (string, error) {
+	name, ok := attr(use.attrs, "name")
+	if !ok {
+		return "", fmt.Errorf("<use> is missing a name")
+	}
+	p, err := lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	data := map[string]interface{}{
+		"Ctx":   CommonConfig,
+		"Theme": theme,
+		"Now":   now,
+	}
+	for _, param := range p.Params {
+		value, ok := attr(use.attrs, param.Name)
+		if !ok && param.Required {
+			return "", fmt.Errorf("partial '%s' requires param '%s'", name, param.Name)
+		}
+		if !ok {
+			value = param.Default
+		}
+		data[param.Name] = value
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Funcs(timeFuncs(now)).Parse(templateVars + p.Body)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse partial '%s': %v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("unable to execute partial '%s': %v", name, err)
+	}
+	body := strings.TrimSpace(buf.String())
+
+	// fill in the slots, falling back to what's inside <slot> when the
+	// <use> has nothing for it
+	slots, err := splitSlots(src[use.inner:use.innerEnd])
+	if err != nil {
+		return "", err
+	}
+	targets, err := scanElements(body, "slot")
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	last := 0
+	for _, target := range targets {
+		slotName, _ := attr(target.attrs, "name")
+		content, ok := slots[slotName]
+		if !ok || strings.TrimSpace(content) == "" {
+			content = body[target.inner:target.innerEnd]
+		}
+		out.WriteString(body[last:target.start])
+		out.WriteString(content)
+		last = target.end
+	}
+	out.WriteString(body[last:])
+	return out.String(), nil
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandPartials(t *testing.T) {
+	partials := map[string]Partial{
+		"label": {
+			Name:   "label",
+			Params: []PartialParam{{Name: "text", Required: true}, {Name: "color", Default: "#FFFFFFFF"}},
+			Body:   `<text color="{{ .color }}">{{ .text }}</text>`,
+		},
+		"box": {
+			Name: "box",
+			Body: `<template><slot name="top"/><slot><text>empty</text></slot></template>`,
+		},
+		"self":   {Name: "self", Body: `<use name="self"/>`},
+		"themed": {Name: "themed", Body: `<text color="{{ $Theme.Primary }}"/>`},
+	}
+	lookup := func(name string) (Partial, error) {
+		p, ok := partials[name]
+		if !ok {
+			return p, fmt.Errorf("no partial named '%s'", name)
+		}
+		return p, nil
+	}
+	expand := func(src string) string {
+		out, err := expandPartials(src, lookup, Theme{Primary: "#123456FF"}, time.Time{})
+		assert.NoError(t, err)
+		return strings.Join(strings.Fields(out), " ")
+	}
+
+	// params, defaults and escaping
+	assert.Equal(t, `<a><text color="#FFFFFFFF">A &amp; B</text></a>`,
+		expand(`<a><use name="label" text="A &amp; B"/></a>`))
+
+	// slots, and nested uses inside them
+	assert.Equal(t, `<template><i slot="top"/><text color="#FF0000FF">hi</text></template>`,
+		expand(`<use name="box"><use name="label" text="hi" color="#FF0000FF"/><i slot="top"/></use>`))
+
+	// an empty slot falls back to what the partial put in it
+	assert.Equal(t, `<template><text>empty</text></template>`, expand(`<use name="box"></use>`))
+
+	// partials see the view's theme
+	assert.Equal(t, `<text color="#123456FF"/>`, expand(`<use name="themed"/>`))
+
+	for _, src := range []string{`<use name="label"/>`, `<use name="missing"/>`, `<use name="self"/>`} {
+		_, err := expandPartials(src, lookup, Theme{}, time.Time{})
+		assert.Error(t, err, src)
+	}
+}
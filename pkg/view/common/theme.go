@@ -0,0 +1,266 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
+)
+
+// Theme the colors, fonts and palette views use, available in every
+// template as $Theme, e.g. color="{{ $Theme.Primary }}", or in any
+// attribute as theme.<field>, e.g. color="theme.primary". Fields left empty
+// fall back to the default theme, which takes its text color and font
+// from the app's defaults
+type Theme struct {
+	Name       string `json:"name"`
+	Background string `json:"background"` // behind everything
+	Text       string `json:"text"`       // everyday text
+	Primary    string `json:"primary"`    // headings and names
+	Secondary  string `json:"secondary"`  // scores and other key numbers
+	Accent     string `json:"accent"`     // small highlights
+	Alert      string `json:"alert"`      // bad news
+	Muted      string `json:"muted"`      // less important text
+	Palette    string `json:"palette"`    // palette name or colors for effects
+	Font       string `json:"font"`
+	FontStyle  string `json:"font-style"`
+	FontSize   int    `json:"font-size"`
+}
+
+const (
+	themePrefix = "TH"
+	// activeThemePrefix where the name of the theme switched to globally
+	// is kept, apart from the themes themselves
+	activeThemePrefix = "AT"
+	DefaultTheme      = "default"
+)
+
+// builtinThemes themes shipped with the app or set in the config file,
+// ones in the store take precedence
+var builtinThemes = map[string]Theme{
+	// dim and red, easy on the eyes in a dark room
+	"night": {
+		Name:       "night",
+		Background: "#000000FF",
+		Text:       "#802018FF",
+		Primary:    "#A03010FF",
+		Secondary:  "#B05000FF",
+		Accent:     "#604000FF",
+		Alert:      "#C00000FF",
+		Muted:      "#401008FF",
+		Palette:    "#000000FF,#400000FF,#802000FF",
+	},
+}
+
+// RegisterTheme adds a built in theme
+func RegisterTheme(t Theme) {
+	builtinThemes[t.Name] = t
+}
+
+// defaultTheme the theme everything falls back to, built from the app's
+// defaults
+func defaultTheme() Theme {
+	t := Theme{
+		Name:       DefaultTheme,
+		Background: "#000000FF",
+		Text:       CommonConfig.DefaultFontColor,
+		Primary:    "#66CCFFFF",
+		Secondary:  "#F2FF00FF",
+		Accent:     "#5FE512FF",
+		Alert:      "#FF4542FF",
+		Muted:      "#888888FF",
+		Font:       CommonConfig.DefaultFontType,
+		FontStyle:  CommonConfig.DefaultFontStyle,
+		FontSize:   CommonConfig.DefaultFontSize,
+	}
+	if t.Text == "" {
+		t.Text = "#FFFFFFFF"
+	}
+	// a default theme set in the config or store adjusts this one
+	if custom, err := GetTheme(DefaultTheme); err == nil {
+		t = t.merge(custom)
+	}
+	t.Name = DefaultTheme
+	return t
+}
+
+// merge returns t with the fields set in other taking its place
+func (t Theme) merge(other Theme) Theme {
+	dst := reflect.ValueOf(&t).Elem()
+	src := reflect.ValueOf(other)
+	for i := 0; i < src.NumField(); i++ {
+		if !src.Field(i).IsZero() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+	return t
+}
+
+// Validate checks that the theme has a name and that the colors it sets
+// parse. The palette can also be an effect's palette name, so it's left to
+// the effects to check
+func (t Theme) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("theme name is required")
+	}
+	for field, value := range map[string]string{
+		"background": t.Background, "text": t.Text, "primary": t.Primary, "secondary": t.Secondary,
+		"accent": t.Accent, "alert": t.Alert, "muted": t.Muted,
+	} {
+		if value == "" {
+			continue
+		}
+		if _, err := colors.Parse(value); err != nil {
+			return fmt.Errorf("theme %s: %v", field, err)
+		}
+	}
+	return nil
+}
+
+// Lookup returns the theme's field with the given json name, e.g. primary
+// or font-size
+func (t Theme) Lookup(name string) (string, bool) {
+	v := reflect.ValueOf(t)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("json") == name {
+			return fmt.Sprint(v.Field(i).Interface()), true
+		}
+	}
+	return "", false
+}
+
+var (
+	attrValueRegex = regexp.MustCompile(`="([^"]*)"`)
+	themeRefRegex  = regexp.MustCompile(`(?i)^(\s*)theme\.([a-z-]+)(\s*)$`)
+)
+
+// resolveThemeRefs swaps theme references in attribute values, e.g.
+// color="theme.primary" or palette="theme.primary,theme.accent", for the
+// theme's values. Only a whole value, or a whole item in a comma separated
+// list, is a reference, so text and URLs that happen to mention one are
+// left alone. Unknown fields are left for the color parsing to report
+func resolveThemeRefs(src string, t Theme) string {
+	return attrValueRegex.ReplaceAllStringFunc(src, func(attr string) string {
+		items := strings.Split(attr[2:len(attr)-1], ",")
+		for i, item := range items {
+			m := themeRefRegex.FindStringSubmatch(item)
+			if m == nil {
+				continue
+			}
+			if value, ok := t.Lookup(strings.ToLower(m[2])); ok {
+				items[i] = m[1] + value + m[3]
+			}
+		}
+		return `="` + strings.Join(items, ",") + `"`
+	})
+}
+
+// SaveTheme saves a theme to the store
+func SaveTheme(t Theme) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = CommonConfig.Store.SaveItem(themePrefix, t.Name, data)
+	return err
+}
+
+// GetTheme looks up a theme by name, in the store and then the built in
+// ones. The default theme is always there
+func GetTheme(name string) (Theme, error) {
+	var t Theme
+	if CommonConfig.Store != nil {
+		data, err := CommonConfig.Store.GetItem(themePrefix + "-" + name)
+		if err != nil {
+			return t, err
+		}
+		if len(data) > 0 {
+			err = json.Unmarshal(data, &t)
+			return t, err
+		}
+	}
+	t, ok := builtinThemes[name]
+	if !ok && name != DefaultTheme {
+		return t, fmt.Errorf("no theme named '%s'", name)
+	}
+	return t, nil
+}
+
+// GetAllThemes retrieves every theme, built in ones included
+func GetAllThemes() ([]Theme, error) {
+	saved := map[string]bool{}
+	var themes []Theme
+	if CommonConfig.Store != nil {
+		datas, err := CommonConfig.Store.GetPrefix(themePrefix + "-")
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range datas {
+			var t Theme
+			if err := json.Unmarshal(data, &t); err != nil {
+				return nil, err
+			}
+			saved[t.Name] = true
+			themes = append(themes, t)
+		}
+	}
+	for name, t := range builtinThemes {
+		if !saved[name] {
+			themes = append(themes, t)
+		}
+	}
+	if _, ok := builtinThemes[DefaultTheme]; !ok && !saved[DefaultTheme] {
+		themes = append(themes, defaultTheme())
+	}
+	return themes, nil
+}
+
+// DeleteTheme deletes a theme from the store
+func DeleteTheme(name string) error {
+	return CommonConfig.Store.DeleteItem(themePrefix + "-" + name)
+}
+
+// ActiveTheme the name of the theme views use unless their definition
+// picks one, the one last switched to or else the config file's
+func ActiveTheme() string {
+	if CommonConfig.Store != nil {
+		if data, err := CommonConfig.Store.GetItem(activeThemePrefix + "-theme"); err == nil && len(data) > 0 {
+			return string(data)
+		}
+	}
+	if CommonConfig.Theme != "" {
+		return CommonConfig.Theme
+	}
+	return DefaultTheme
+}
+
+// SetActiveTheme switches every view without a theme of its own over to
+// the named theme
+func SetActiveTheme(name string) error {
+	if _, err := GetTheme(name); err != nil {
+		return err
+	}
+	_, err := CommonConfig.Store.SaveItem(activeThemePrefix, "theme", []byte(name))
+	return err
+}
+
+// ResolveTheme the theme to render with, the named one or the active one
+// if name is empty, filled in from the default theme. A theme that can't
+// be found falls back to the default
+func ResolveTheme(name string) Theme {
+	if name == "" {
+		name = ActiveTheme()
+	}
+	t, err := GetTheme(name)
+	if err != nil {
+		log.Printf("Unable to load theme, using the default: '%v'", err)
+		return defaultTheme()
+	}
+	resolved := defaultTheme().merge(t)
+	resolved.Name = name
+	return resolved
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveTheme(t *testing.T) {
+	prevConfig, prevThemes := CommonConfig, builtinThemes
+	defer func() { CommonConfig, builtinThemes = prevConfig, prevThemes }()
+	CommonConfig = &ViewCommonConfig{DefaultFontType: "Go", DefaultFontSize: 8}
+	builtinThemes = map[string]Theme{}
+
+	// the default theme comes from the app's defaults
+	theme := ResolveTheme("")
+	assert.Equal(t, DefaultTheme, theme.Name)
+	assert.Equal(t, "#FFFFFFFF", theme.Text)
+	assert.Equal(t, "Go", theme.Font)
+	assert.Equal(t, 8, theme.FontSize)
+
+	// themes only need the fields they change, and a default theme in the
+	// config adjusts what the rest fall back to
+	RegisterTheme(Theme{Name: DefaultTheme, Primary: "#0000FFFF"})
+	RegisterTheme(Theme{Name: "night", Text: "#800000FF", FontSize: 6})
+	theme = ResolveTheme("night")
+	assert.Equal(t, "night", theme.Name)
+	assert.Equal(t, "#800000FF", theme.Text)
+	assert.Equal(t, "#0000FFFF", theme.Primary)
+	assert.Equal(t, "Go", theme.Font)
+	assert.Equal(t, 6, theme.FontSize)
+
+	// switched globally in the config, and missing ones fall back
+	CommonConfig.Theme = "night"
+	assert.Equal(t, "night", ResolveTheme("").Name)
+	theme = ResolveTheme("missing")
+	assert.Equal(t, DefaultTheme, theme.Name)
+	assert.Equal(t, "#0000FFFF", theme.Primary)
+
+	assert.Error(t, Theme{}.Validate())
+	assert.Error(t, Theme{Name: "bad", Primary: "#12"}.Validate())
+	assert.NoError(t, Theme{Name: "named", Primary: "teal", Accent: "hsl(30, 100%, 50%)"}.Validate())
+}
+
+func TestResolveThemeRefs(t *testing.T) {
+	theme := Theme{Primary: "#0000FFFF", Accent: "red", FontSize: 6}
+	src := `<text color="theme.primary" size="Theme.Font-Size" palette="theme.primary, theme.accent" bg-color="theme.nope">theme.primary</text>`
+	assert.Equal(t,
+		`<text color="#0000FFFF" size="6" palette="#0000FFFF, red" bg-color="theme.nope">theme.primary</text>`,
+		resolveThemeRefs(src, theme))
+
+	// only whole values are references, not ones that mention them
+	src = `<image src="https://example.com/theme.primary.png" alt="I like theme.primary" data="theme.primary.png"></image>`
+	assert.Equal(t,
+		`<image src="https://example.com/theme.primary.png" alt="I like theme.primary" data="theme.primary.png"></image>`,
+		resolveThemeRefs(src, theme))
+}
@@ -178,6 +178,7 @@ func generateFieldSpecs(v interface{}, parentField string) []ViewConfigFieldSpec
 			Value:    field.Value,
 			Kind:     field.Kind,
 			Label:    getStrTag(field.Tags, "label"),
+			Color:    getBoolTag(field.Tags, "color"),
 		}
 		specs = append(specs, spec)
 
@@ -244,6 +245,7 @@ func mapViewConfigSpec(spec ViewConfigFieldSpec) map[string]interface{} {
 		"required": spec.Required,
 		"min":      spec.Min,
 		"max":      spec.Max,
+		"color":    spec.Color,
 	}
 	return specMap
 }
@@ -5,12 +5,15 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"github.com/6ixisgood/matrix-ticker/pkg/colors"
 	compCommon "github.com/6ixisgood/matrix-ticker/pkg/component/common"
 	"github.com/6ixisgood/matrix-ticker/pkg/store"
 	"html/template"
+	"image"
 	"log"
 	"maps"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -22,6 +25,8 @@ type View interface {
 	SetTemplateValue(compCommon.Template)
 	TemplateString() string
 	TemplateData() map[string]interface{}
+	SetTheme(string)
+	ThemeName() string
 	Stop()
 }
 
@@ -37,6 +42,7 @@ type ViewCommonConfig struct {
 	DefaultFontColor  string
 	DefaultFontStyle  string
 	DefaultFontType   string
+	Theme             string // theme views use unless switched or picked per view, defaults to "default"
 	Store             *store.Store
 }
 
@@ -48,6 +54,7 @@ type ViewDefinition struct {
 	Id     string     `json:"id" spec:"label='View Definition ID',required='true'"`
 	Name   string     `json:"name" spec:"label='View Definition Name',required='true'"`
 	Type   string     `json:"type" spec:"label='View Type',required='true'"`
+	Theme  string     `json:"theme" spec:"label='Theme',required='false'"`
 	Config ViewConfig `json:"config" spec:"label='View Config',required='true'"`
 }
 
@@ -56,6 +63,7 @@ type ViewDefinitionRaw struct {
 	Id     string          `json:"id"`
 	Name   string          `json:"name"`
 	Type   string          `json:"type"`
+	Theme  string          `json:"theme"`
 	Config json.RawMessage `json:"config"`
 }
 
@@ -78,35 +86,8 @@ func RegisterView(name string, creator RegisteredView) {
 	RegisteredViews[name] = creator
 }
 
-// TemplateRefresh static function to generate a View's template
-func TemplateRefresh(v View) {
-	// create the template object
-	tmpl := template.New("view-template")
-
-	// gather all the custom functions
-	funcMap := template.FuncMap{
-		"NilOrDefault": func() string { return "N/A" },
-		"CardinalToOrdinal": func(card int) string {
-			switch card {
-			case 0:
-				return "0th"
-			case 1:
-				return "1st"
-			case 2:
-				return "2nd"
-			case 3:
-				return "3rd"
-			case 4:
-				return "4th"
-			default:
-				return "N/A"
-			}
-		},
-	}
-	tmpl = tmpl.Funcs(funcMap)
-
-	// construct the template string
-	tmplString := `
+// templateVars variables defined at the top of every template
+var templateVars = `
 		{{ $MatrixSizex := .Ctx.MatrixCols }}
 		{{ $MatrixSizey := .Ctx.MatrixRows }}
 		{{ $DefaultImageSizex := .Ctx.DefaultImageSizeX }}
@@ -117,10 +98,29 @@ func TemplateRefresh(v View) {
 		{{ $DefaultFontColor := .Ctx.DefaultFontColor }}
 		{{ $ImageDir := .Ctx.ImageDir }}
 		{{ $CacheDir := .Ctx.CacheDir }}
-
-		%s
+		{{ $Theme := .Theme }}
 	`
-	tmplString = fmt.Sprintf(tmplString, v.TemplateString())
+
+// refreshMu one refresh at a time, so two can't take over the same running
+// components
+var refreshMu sync.Mutex
+
+// RenderView draws a frame of the view's template, never while a refresh is
+// swapping it out. The image is reused for the next frame
+func RenderView(v View, ctx *compCommon.RenderContext) image.Image {
+	compCommon.RenderMu.Lock()
+	defer compCommon.RenderMu.Unlock()
+	return v.Template().Render(ctx)
+}
+
+// TemplateRefresh static function to generate a View's template
+func TemplateRefresh(v View) {
+	// create the template object
+	now := Now()
+	tmpl := template.New("view-template").Funcs(templateFuncs).Funcs(timeFuncs(now))
+
+	// construct the template string
+	tmplString := templateVars + v.TemplateString()
 
 	// parse the template string from the view
 	tmpl, err := tmpl.Parse(tmplString)
@@ -130,8 +130,11 @@ func TemplateRefresh(v View) {
 	}
 
 	// merge data maps
+	theme := ResolveTheme(v.ThemeName())
 	data := map[string]interface{}{
-		"Ctx": CommonConfig,
+		"Ctx":   CommonConfig,
+		"Theme": theme,
+		"Now":   now,
 	}
 	maps.Copy(data, v.TemplateData())
 
@@ -143,8 +146,15 @@ func TemplateRefresh(v View) {
 		panic(err)
 	}
 
-	// convert to string
-	tmplStr := buf.String()
+	// swap in any partials the template uses
+	tmplStr, err := expandPartials(buf.String(), GetPartial, theme, now)
+	if err != nil {
+		// partials can be edited while views use them, keep showing the
+		// running template until they're fixed
+		log.Printf("Unable to expand partials, keeping the running template: '%v'", err)
+		return
+	}
+	tmplStr = resolveThemeRefs(tmplStr, theme)
 
 	// unmarshall the string
 	t := compCommon.Template{}
@@ -153,9 +163,19 @@ func TemplateRefresh(v View) {
 		log.Fatalf("Unable to unmarshal xml content: '%v'", err)
 	}
 
-	// set new template and init
+	// carry over whatever hasn't changed from the running template, then
+	// init the rest while the running one is still drawn, and swap it in
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+	compCommon.RenderMu.Lock()
+	if prev := v.Template(); prev != nil && prev.Ready() {
+		t.Reconcile(prev)
+	}
+	compCommon.RenderMu.Unlock()
 	t.Init()
+	compCommon.RenderMu.Lock()
 	v.SetTemplateValue(t)
+	compCommon.RenderMu.Unlock()
 }
 
 type ViewConfigFieldSpec struct {
@@ -168,6 +188,7 @@ type ViewConfigFieldSpec struct {
 	Min      int
 	Max      int
 	Label    string
+	Color    bool // a color, or a comma separated list of them
 }
 
 // ValidateViewConfig takes a ViewConfig and using the rules defined by
@@ -217,6 +238,13 @@ func ValidateViewConfig(v ViewConfig) error {
 			if validator.Max != 0 && len(str) > validator.Max {
 				return fmt.Errorf("field %s must have no more than %d characters", validator.Field, validator.Max)
 			}
+			if validator.Color && str != "" {
+				for _, item := range colors.SplitList(str) {
+					if err := colors.Valid(item); err != nil {
+						return fmt.Errorf("field %s: %v", validator.Field, err)
+					}
+				}
+			}
 		case reflect.Slice:
 			sliceVal := reflect.ValueOf(validator.Value)
 			if sliceVal.Kind() != reflect.Slice {
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	compCommon "github.com/6ixisgood/matrix-ticker/pkg/component/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// slow a component that takes until release is closed to initialize, like
+// an image being fetched
+type slow struct {
+	compCommon.BaseComponent
+}
+
+var started, release chan struct{}
+
+func (s *slow) Init() {
+	s.BaseComponent.Init()
+	close(started)
+	<-release
+}
+
+func (s *slow) Render(ctx *compCommon.RenderContext) image.Image {
+	return image.NewRGBA(image.Rect(0, 0, 1, 1))
+}
+
+func init() {
+	compCommon.RegisterComponent("slow", func() compCommon.Component { return &slow{} })
+}
+
+type testView struct {
+	BaseView
+	tmpl string
+}
+
+func (v *testView) TemplateString() string {
+	return v.tmpl
+}
+
+func TestTemplateRefreshKeepsRendering(t *testing.T) {
+	v := &testView{tmpl: `<template size-x="4" size-y="4"></template>`}
+	v.Init()
+	TemplateRefresh(v)
+
+	started, release = make(chan struct{}), make(chan struct{})
+	v.tmpl = `<template size-x="4" size-y="4"><slow/></template>`
+	refreshed := make(chan struct{})
+	go func() {
+		TemplateRefresh(v)
+		close(refreshed)
+	}()
+	<-started
+
+	// the running template is still drawn while the new one initializes
+	rendered := make(chan struct{})
+	go func() {
+		RenderView(v, compCommon.NewClock(time.Now(), 0).Frame(time.Now()))
+		close(rendered)
+	}()
+	select {
+	case <-rendered:
+	case <-time.After(time.Second):
+		t.Fatal("rendering waited for the refresh")
+	}
+	assert.Empty(t, v.Template().Components)
+
+	close(release)
+	<-refreshed
+	assert.Len(t, v.Template().Components, 1)
+}
+
+func TestTemplateRefreshMissingPartial(t *testing.T) {
+	v := &testView{tmpl: `<template size-x="4" size-y="4"><template size-x="1" size-y="1"></template></template>`}
+	v.Init()
+	TemplateRefresh(v)
+	running := v.Template().Components[0]
+
+	// a partial that's gone leaves the running template in place
+	v.tmpl = `<template size-x="4" size-y="4"><use name="missing"/></template>`
+	TemplateRefresh(v)
+	assert.Len(t, v.Template().Components, 1)
+	assert.Same(t, running, v.Template().Components[0])
+}
@@ -47,7 +47,15 @@ func (a *Animation) Init(newView viewCommon.View) {
 	a.buffer = make(chan image.Image, 10)
 
 	// Start the new rendering task
-	go a.startRendering(a.ctx)
+	go a.startRendering(a.ctx, newView)
+}
+
+// Refresh redraws the current view's template, e.g. after the theme
+// changes
+func (a *Animation) Refresh() {
+	if a.view != nil {
+		viewCommon.TemplateRefresh(a.view)
+	}
 }
 
 func cloneImage(img image.Image) image.Image {
@@ -57,7 +65,8 @@ func cloneImage(img image.Image) image.Image {
 	return dst
 }
 
-func (a *Animation) startRendering(ctx context.Context) {
+func (a *Animation) startRendering(ctx context.Context, view viewCommon.View) {
+	clock := compCommon.NewClock(time.Now(), time.Now().UnixNano())
 	for {
 		select {
 		case <-ctx.Done():
@@ -65,7 +74,7 @@ func (a *Animation) startRendering(ctx context.Context) {
 			return
 		default:
 			if len(a.buffer) < cap(a.buffer) {
-				im := cloneImage(a.view.Template().Render())
+				im := cloneImage(viewCommon.RenderView(view, clock.Frame(time.Now())))
 				a.buffer <- im
 			} else {
 				time.Sleep(100 * time.Millisecond)
@@ -0,0 +1,139 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/6ixisgood/matrix-ticker/pkg/component/golden"
+	_ "github.com/6ixisgood/matrix-ticker/pkg/component/types"
+	d "github.com/6ixisgood/matrix-ticker/pkg/data"
+	c "github.com/6ixisgood/matrix-ticker/pkg/view/common"
+)
+
+const ms = time.Millisecond
+
+// goldenView renders an initialized view's template and checks the frames
+// against the golden images for name
+func goldenView(t *testing.T, name string, v c.View, at ...time.Duration) {
+	t.Helper()
+	c.TemplateRefresh(v)
+	defer v.Template().Stop()
+	golden.Check(t, name, golden.Frames(v.Template(), at...))
+}
+
+// create builds a view of the given type from its config
+func create(t *testing.T, viewType string, config c.ViewConfig) c.View {
+	t.Helper()
+	v, err := c.RegisteredViews[viewType].NewView(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Init()
+	return v
+}
+
+func TestGolden(t *testing.T) {
+	golden.Fonts(t)
+	prev := c.CommonConfig
+	c.SetViewCommonConfig(&c.ViewCommonConfig{
+		MatrixCols:        128,
+		MatrixRows:        64,
+		DefaultImageSizeX: 16,
+		DefaultImageSizeY: 16,
+		DefaultFontSize:   8,
+		DefaultFontColor:  "#FFFFFFFF",
+		DefaultFontType:   "Go",
+		DefaultFontStyle:  "Regular",
+	})
+	defer c.SetViewCommonConfig(prev)
+	// templates see the same time the frames are rendered at
+	c.Now = func() time.Time { return golden.Start }
+	defer func() { c.Now = time.Now }()
+
+	t.Run("text", func(t *testing.T) {
+		v := create(t, "text", &TextViewConfig{Text: "Hello, world", Justify: "center", Alignment: "center"})
+		goldenView(t, "text", v, 0)
+	})
+
+	t.Run("image", func(t *testing.T) {
+		v := create(t, "image", &ImagePlayerViewConfig{Src: "testdata/avatar.png"})
+		goldenView(t, "image", v, 0)
+	})
+
+	t.Run("text-image", func(t *testing.T) {
+		v := create(t, "text-image", &TextImageViewConfig{Text: "Hi", FontSize: 12, Src: "testdata/avatar.png", Justify: "center", Alignment: "center"})
+		goldenView(t, "text-image", v, 0)
+	})
+
+	t.Run("particle", func(t *testing.T) {
+		v := create(t, "particle", &ParticlesViewConfig{})
+		goldenView(t, "particle", v, 0, 100*ms, 200*ms)
+	})
+
+	t.Run("template", func(t *testing.T) {
+		v := create(t, "template", &TemplateViewConfig{Template: `
+			<template size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}" justify="space-around" align="center">
+				<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" size="12" color="#FF8800FF">{{ 3.14159 | fixed 2 }} {{ now | formatTime "3:04" }}</text>
+				<qr size-x="40" size-y="40" data="matrix" quiet-zone="2"></qr>
+			</template>
+		`})
+		goldenView(t, "template", v, 0)
+	})
+
+	t.Run("nflbox", func(t *testing.T) {
+		v := &NFLBoxView{Game: d.NFLBoxScoreResponseFormatted{
+			HomeLogo: "testdata/avatar.png", AwayLogo: "testdata/avatar.png",
+			HomeScore: 21, AwayScore: 17,
+			Quarter: 4, QuarterMinRemaining: 2, QuarterSecRemaining: 30,
+			Down: 3, YardsRemaining: 7, LineOfScrimmage: 35,
+			HomePassYards: 250, AwayPassYards: 198, HomeRushYards: 88, AwayRushYards: 120,
+			HomeSacks: 3, AwaySacks: 1,
+			HomeWins: 10, HomeLosses: 4, AwayWins: 8, AwayLosses: 6, AwayTies: 1,
+		}}
+		v.BaseView.Init()
+		goldenView(t, "nflbox", v, 0)
+	})
+
+	t.Run("sleeper-matchups", func(t *testing.T) {
+		team := func(name string, score float64) d.SleeperTeamFormatted {
+			return d.SleeperTeamFormatted{
+				Name:   name,
+				Avatar: "testdata/avatar.png",
+				Score:  score,
+				Starters: []d.SleeperPlayerFormatted{
+					{Name: "J. Allen", Points: 24.1, Position: "QB"},
+					{Name: "C. McCaffrey", Points: 18.4, Position: "RB"},
+					{Name: "J. Jefferson", Points: 15.7, Position: "WR"},
+					{Name: "T. Kelce", Points: 9.3, Position: "TE"},
+					{Name: "J. Tucker", Points: 8.0, Position: "K"},
+					{Name: "Eagles", Points: 7.0, Position: "DEF"},
+					{Name: "D. Henry", Points: 12.9, Position: "FLEX"},
+				},
+				Bench: []d.SleeperPlayerFormatted{
+					{Name: "T. Lockett", Points: 6.2, Position: "WR"},
+				},
+			}
+		}
+		v := &SleeperMatchupsView{
+			Week:     3,
+			league:   d.SleeperLeagueFormatted{Name: "League", StartingPositions: []string{"QB", "RB", "WR", "TE", "K", "DEF", "FLEX"}},
+			matchups: [][]d.SleeperTeamFormatted{{team("Home", 102.5), team("Away", 98.25)}},
+		}
+		for phase := 0; phase < 3; phase++ {
+			v.BaseView.Init()
+			v.Phase = phase
+			at := []time.Duration{0}
+			if phase == 1 {
+				// the starters don't all fit, the list moves on to the rest
+				at = append(at, 5*time.Second)
+			}
+			goldenView(t, fmt.Sprintf("sleeper-matchups-phase%d", phase), v, at...)
+		}
+
+		v.BaseView.Init()
+		v.Phase = 1
+		v.SetTheme("night")
+		goldenView(t, "sleeper-matchups-night", v, 0)
+	})
+}
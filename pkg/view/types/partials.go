@@ -0,0 +1,29 @@
+package types
+
+import (
+	c "github.com/6ixisgood/matrix-ticker/pkg/view/common"
+)
+
+func init() {
+	// a team's name, logo and score stacked in a column
+	c.RegisterPartial(c.Partial{
+		Name: "team-header",
+		Params: []c.PartialParam{
+			{Name: "team", Required: true},
+			{Name: "logo", Required: true},
+			{Name: "score", Required: true},
+			{Name: "color"},       // defaults to the theme's primary color
+			{Name: "score-color"}, // defaults to the theme's secondary color
+			{Name: "score-size", Default: "16"},
+			{Name: "size-x", Default: "50%"},
+			{Name: "size-y", Default: "100%"},
+		},
+		Body: `
+			<template justify="space-around" align="center" size-x="{{ index . "size-x" }}" size-y="{{ index . "size-y" }}" dir="col">
+				<text size-x="90%" font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ or .color $Theme.Primary }}" size="{{ $Theme.FontSize }}">{{ .team }}</text>
+				<image size-x="{{ $DefaultImageSizex }}" size-y="{{ $DefaultImageSizey }}" src="{{ .logo }}"></image>
+				<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ or (index . "score-color") $Theme.Secondary }}" size="{{ index . "score-size" }}"> {{ .score }}</text>
+			</template>
+		`,
+	})
+}
@@ -91,6 +91,7 @@ func PlaylistViewCreate(viewConfig c.ViewConfig) (c.View, error) {
 		if err != nil {
 			return nil, errors.New(fmt.Sprintf("Failed to create view of type %s with given config\nError: %s", viewDef.Type, err))
 		}
+		newView.SetTheme(viewDef.Theme)
 
 		views = append(views, newView)
 		time := defaultTime
@@ -120,6 +121,17 @@ func (v *PlaylistView) TemplateData() map[string]interface{} {
 	return v.views[v.activeIndex].TemplateData()
 }
 
+// ThemeName the active view's theme if its definition picks one, the
+// playlist's otherwise
+func (v *PlaylistView) ThemeName() string {
+	if v.activeIndex >= 0 {
+		if name := v.views[v.activeIndex].ThemeName(); name != "" {
+			return name
+		}
+	}
+	return v.BaseView.ThemeName()
+}
+
 func (v *PlaylistView) NextView() {
 	select {
 	case <-v.ctx.Done():
@@ -116,43 +116,43 @@ func (v *NFLBoxView) TemplateString() string {
 		{{ $ScoreFontSize := 32 }}
 		{{ $DetailFontSize := 14}}
 		{{ $RecordFontSize := 10}}
-		{{ $ScoreFontColor := "#F2FF00FF" }}
+		{{ $ScoreFontColor := $Theme.Secondary }}
 		{{ $LogoSize := 64 }}
 
-		<template dir="col" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}">
+		<template dir="col" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}" bg-color="{{ $Theme.Background }}">
 
 			<template justify="space-around" size-x="100%" size-y="50%">
 				<template dir="col" justify="space-between" align="center"  size-x="40%" size-y="100%">
 		    		<image size-x="{{ $LogoSize }}" size-y="{{ $LogoSize }}" src="{{ .Game.AwayLogo }}"></image>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $RecordFontSize }}">({{ .Game.AwayWins }}-{{ .Game.AwayLosses }}-{{ .Game.AwayTies }})</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreFontColor }}" size="{{ $ScoreFontSize }}">{{ .Game.AwayScore }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $RecordFontSize }}">({{ .Game.AwayWins }}-{{ .Game.AwayLosses }}-{{ .Game.AwayTies }})</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $ScoreFontColor }}" size="{{ $ScoreFontSize }}">{{ .Game.AwayScore }}</text>
 				</template>
 
 				<template dir="col" justify="space-around" align="center" size-x="20%" size-y="100%">
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">{{ .Game.QuarterMinRemaining }}:{{ .Game.QuarterSecRemaining }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">{{ CardinalToOrdinal .Game.Quarter}}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">{{ CardinalToOrdinal .Game.Down}}{{ "&" }}{{ .Game.YardsRemaining }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">{{ .Game.LineOfScrimmage }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">{{ .Game.QuarterMinRemaining }}:{{ .Game.QuarterSecRemaining }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">{{ CardinalToOrdinal .Game.Quarter}}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">{{ CardinalToOrdinal .Game.Down}}{{ "&" }}{{ .Game.YardsRemaining }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">{{ .Game.LineOfScrimmage }}</text>
 				</template>
 
 				<template dir="col" justify="space-between"  align="center" size-x="40%" size-y="100%">
 		    		<image size-x="{{ $LogoSize }}" size-y="{{ $LogoSize }}" src="{{ .Game.HomeLogo }}"></image>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $RecordFontSize }}">({{ .Game.HomeWins }}-{{ .Game.HomeLosses }}-{{ .Game.HomeTies }})</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreFontColor }}" size="{{ $ScoreFontSize }}">{{ .Game.HomeScore }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $RecordFontSize }}">({{ .Game.HomeWins }}-{{ .Game.HomeLosses }}-{{ .Game.HomeTies }})</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $ScoreFontColor }}" size="{{ $ScoreFontSize }}">{{ .Game.HomeScore }}</text>
 				</template>
 			</template>
 
 			<template justify="space-between" size-x="100%" size-y="50%">
 				<template size-x="45%" size-y="100%" dir="col" justify="space-around">
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Passing: {{ .Game.AwayPassYards }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Rushing: {{ .Game.AwayRushYards }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Sacks: {{ .Game.AwaySacks }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Passing: {{ .Game.AwayPassYards }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Rushing: {{ .Game.AwayRushYards }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Sacks: {{ .Game.AwaySacks }}</text>
 				</template>
 
 				<template size-x="45%" size-y="100%" dir="col" justify="space-around">
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Passing: {{ .Game.HomePassYards }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Rushing: {{ .Game.HomeRushYards }}</text>
-					<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DetailFontSize }}">Sacks: {{ .Game.HomeSacks }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Passing: {{ .Game.HomePassYards }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Rushing: {{ .Game.HomeRushYards }}</text>
+					<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $DetailFontSize }}">Sacks: {{ .Game.HomeSacks }}</text>
 				</template>
 			</template>
 
@@ -89,10 +89,46 @@ func (v *SleeperMatchupsView) RefreshPhase() {
 	c.TemplateRefresh(v)
 }
 
+// matchupRow a line of the player table, a player from each team side by
+// side with the position they're starting at
+type matchupRow struct {
+	Home     *d.SleeperPlayerFormatted
+	Position string
+	Away     *d.SleeperPlayerFormatted
+}
+
+// rows lines up the starters, or the bench in the second phase, of both
+// teams. Either team can run out of players first
+func (v *SleeperMatchupsView) rows() []matchupRow {
+	home, away := v.matchups[v.matchIndex][0].Starters, v.matchups[v.matchIndex][1].Starters
+	positions := v.league.StartingPositions
+	if v.Phase == 2 {
+		home, away = v.matchups[v.matchIndex][0].Bench, v.matchups[v.matchIndex][1].Bench
+		positions = nil
+	}
+
+	var rows []matchupRow
+	for i := 0; i < len(home) || i < len(away) || i < len(positions); i++ {
+		var row matchupRow
+		if i < len(home) {
+			row.Home = &home[i]
+		}
+		if i < len(positions) {
+			row.Position = positions[i]
+		}
+		if i < len(away) {
+			row.Away = &away[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
 func (v *SleeperMatchupsView) TemplateData() map[string]interface{} {
 	return map[string]interface{}{
 		"Team1":  v.matchups[v.matchIndex][0],
 		"Team2":  v.matchups[v.matchIndex][1],
+		"Rows":   v.rows(),
 		"League": v.league,
 		"Phase":  v.Phase,
 		"Week":   v.Week,
@@ -106,85 +142,43 @@ func (v *SleeperMatchupsView) Stop() {
 
 func (v *SleeperMatchupsView) TemplateString() string {
 	return `
-			{{ $BenchedColor := "#FF4542FF" }}
-			{{ $ScoreColor := "#F2FF00FF" }}
-			{{ $PlayingColor := "#FFFFFFFF"}}
-			{{ $TeamNameColor := "#66CCFFFF"}}
-			{{ $PositionColor := "#5FE512FF"}}
+			{{ $BenchedColor := $Theme.Alert }}
+			{{ $ScoreColor := $Theme.Secondary }}
+			{{ $PlayingColor := $Theme.Text }}
+			{{ $TeamNameColor := $Theme.Primary }}
+			{{ $PositionColor := $Theme.Accent }}
 	
 			{{ if eq .Phase 0 }}
 	
-			<template dir="col" justify="center" align="center" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}">
-				<rainbow-text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" size="12" color="{{ $DefaultFontColor }}">{{ .League.Name }}</rainbow-text>
-				<rainbow-text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" size="12" color="{{ $DefaultFontColor }}">Week {{ .Week }}</rainbow-text>
+			<template dir="col" justify="center" align="center" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}" bg-color="{{ $Theme.Background }}">
+				<rainbow-text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" size="12" color="{{ $Theme.Text }}">{{ .League.Name }}</rainbow-text>
+				<rainbow-text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" size="12" color="{{ $Theme.Text }}">Week {{ .Week }}</rainbow-text>
 			</template>
 	
 			{{ else if gt .Phase 0 }}
-			<template justify="space-between" align="center" dir="col" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}">
+			<template justify="space-between" align="center" dir="col" size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}" bg-color="{{ $Theme.Background }}">
 
 				<!-- Team Headers -->
 				<template size-x="100%" size-y="35%">
-					<template justify="space-around" align="center" size-x="50%" size-y="100%" dir="col">
-						<text size-x="90%" font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $TeamNameColor }}" size="{{ $DefaultFontSize }}">{{ .Team1.Name }}</text>
-						<image size-x="{{ $DefaultImageSizex }}" size-y="{{ $DefaultImageSizey }}" src="{{ .Team1.Avatar }}"></image>
-						<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreColor }}" size="16"> {{ .Team1.Score }}</text>
-					</template>
-					<template justify="space-around" align="center" size-x="50%" size-y="100%" dir="col">
-						<text size-x="90%" font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $TeamNameColor }}" size="{{ $DefaultFontSize }}">{{ .Team2.Name }}</text>
-						<image size-x="{{ $DefaultImageSizex }}" size-y="{{ $DefaultImageSizey }}" src="{{ .Team2.Avatar }}"></image>
-						<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreColor }}" size="16"> {{ .Team2.Score }}</text>
-					</template>
+					<use name="team-header" team="{{ .Team1.Name }}" logo="{{ .Team1.Avatar }}" score="{{ .Team1.Score }}" color="{{ $TeamNameColor }}" score-color="{{ $ScoreColor }}"/>
+					<use name="team-header" team="{{ .Team2.Name }}" logo="{{ .Team2.Avatar }}" score="{{ .Team2.Score }}" color="{{ $TeamNameColor }}" score-color="{{ $ScoreColor }}"/>
 				</template>
 
 
-				<!-- Player Info -->
-				<template size-x="100%" size-y="65%">
-					<template dir="col" size-x="45%" size-y="100%" justify="space-around">
-						{{ if eq .Phase 1 }}	
-							{{ range $index, $element := .Team1.Starters }}
-							<template size-x="100%" size-y="10%" justify="space-between">
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DefaultFontSize }}">{{ printf "%s" $element.Name }}</text>
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreColor }}" size="{{ $DefaultFontSize }}">{{ printf "%.2f" $element.Points }}</text>
-							</template>
-							{{ end }}
-						{{ else if eq .Phase 2 }}
-							{{ range $index, $element := .Team1.Bench }}
-							<template size-x="100%" size-y="10%" justify="space-between">
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DefaultFontSize }}">{{ printf "%s" $element.Name }}</text>
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $BenchedColor }}" size="{{ $DefaultFontSize }}">{{ printf "%.2f" $element.Points }}</text>
-							</template>
-							{{ end }}
-						{{ end }}
-					</template>
-
-					<template dir="col" size-x="10%" size-y="100%" justify="space-around">
-						{{ if eq .Phase 1 }}
-							{{ range $index, $element := .League.StartingPositions }}
-							<template size-x="100%" size-y="10%" justify="center">
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $PositionColor }}" size="{{ $DefaultFontSize }}">{{ printf "%s" $element }}</text>
-							</template>
-							{{ end }}
-						{{ end}}
-					</template>
-
-					<template dir="col" size-x="45%" size-y="100%" justify="space-around">
-						{{ if eq .Phase 1 }}	
-							{{ range $index, $element := .Team2.Starters }}
-							<template size-x="100%" size-y="10%" justify="space-between">
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $ScoreColor }}" size="{{ $DefaultFontSize }}">{{ printf "%.2f" $element.Points }}</text>
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DefaultFontSize }}">{{ printf "%s" $element.Name }}</text>
-							</template>
-							{{ end }}
-						{{ else if eq .Phase 2 }}
-							{{ range $index, $element := .Team2.Bench }}
-							<template size-x="100%" size-y="10%" justify="space-between">
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $BenchedColor }}" size="{{ $DefaultFontSize }}">{{ printf "%.2f" $element.Points }}</text>
-								<text font="{{ $DefaultFontType }}" style="{{ $DefaultFontStyle }}" color="{{ $DefaultFontColor }}" size="{{ $DefaultFontSize }}">{{ printf "%s" $element.Name }}</text>
-							</template>
-							{{ end }}
-						{{ end }}
-					</template>
-				</template>
+				<!-- Player Info, paging through rosters too long to fit. Column widths are fixed so they line up from page to page -->
+				{{ $PointsColor := $ScoreColor }}
+				{{ if eq .Phase 2 }}{{ $PointsColor = $BenchedColor }}{{ end }}
+				<list size-x="100%" size-y="65%" interval="4s">
+					{{ range .Rows }}
+					<li><table size-x="100%" cols="23%,18%,auto,18%,23%" align="left,right,center,left,right" gap-x="1"><tr>
+						<td>{{ with .Home }}<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $Theme.FontSize }}">{{ .Name }}</text>{{ end }}</td>
+						<td>{{ with .Home }}<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $PointsColor }}" size="{{ $Theme.FontSize }}">{{ printf "%.2f" .Points }}</text>{{ end }}</td>
+						<td>{{ with .Position }}<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $PositionColor }}" size="{{ $Theme.FontSize }}">{{ . }}</text>{{ end }}</td>
+						<td>{{ with .Away }}<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $PointsColor }}" size="{{ $Theme.FontSize }}">{{ printf "%.2f" .Points }}</text>{{ end }}</td>
+						<td>{{ with .Away }}<text font="{{ $Theme.Font }}" style="{{ $Theme.FontStyle }}" color="{{ $Theme.Text }}" size="{{ $Theme.FontSize }}">{{ .Name }}</text>{{ end }}</td>
+					</tr></table></li>
+					{{ end }}
+				</list>
 			 </template>
 
 			 {{ end }}
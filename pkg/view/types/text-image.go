@@ -23,8 +23,8 @@ type TextImageViewConfig struct {
 	FontSize  int `json:"font-size" spec:"required='false',min='1',label='Font Size'"`
 	Alignment string `json:"alignment" spec:"required='false',label='Alignment'"`
 	Justify   string `json:"justify" spec:"required='false',label='Justify'"`
-	Color     string `json:"color" spec:"required='false',label='Color'"`
-	BgColor   string `json:"bg-color" spec:"required='false',label='Background Color'"`
+	Color     string `json:"color" spec:"required='false',color='true',label='Color'"`
+	BgColor   string `json:"bg-color" spec:"required='false',color='true',label='Background Color'"`
 	Src string `json:"src" spec:"required='true',label='Src (URL/Filepath)'"`
 
 }
@@ -40,11 +40,11 @@ func TextImageViewCreate(viewConfig c.ViewConfig) (c.View, error) {
 	}
 
 	if config.Color == "" {
-		config.Color = "#FFFFFFFF"
+		config.Color = "theme.text"
 	}
 
 	if config.BgColor == "" {
-		config.BgColor = "#00000FF"
+		config.BgColor = "theme.background"
 	}
 
 	if config.FontSize == 0 {
@@ -19,8 +19,8 @@ type TextViewConfig struct {
 	Text      string `json:"text" spec:"required='true',min='1',label='Text'"`
 	Alignment string `json:"alignment" spec:"required='false',label='Alignment'"`
 	Justify   string `json:"justify" spec:"required='false',label='Justify'"`
-	Color     string `json:"color" spec:"required='false',label='Color'"`
-	BgColor   string `json:"bg-color" spec:"required='false',label='Background Color'"`
+	Color     string `json:"color" spec:"required='false',color='true',label='Color'"`
+	BgColor   string `json:"bg-color" spec:"required='false',color='true',label='Background Color'"`
 }
 
 func TextViewCreate(viewConfig c.ViewConfig) (c.View, error) {
@@ -34,11 +34,11 @@ func TextViewCreate(viewConfig c.ViewConfig) (c.View, error) {
 	}
 
 	if config.Color == "" {
-		config.Color = "#FFFFFFFF"
+		config.Color = "theme.text"
 	}
 
 	if config.BgColor == "" {
-		config.BgColor = "#00000FF"
+		config.BgColor = "theme.background"
 	}
 
 	return &TextView{
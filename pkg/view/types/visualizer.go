@@ -0,0 +1,73 @@
+package types
+
+import (
+	"errors"
+	c "github.com/6ixisgood/matrix-ticker/pkg/view/common"
+)
+
+type VisualizerView struct {
+	c.BaseView
+
+	Src        string
+	Mode       string
+	Palette    string
+	Encoding   string
+	SampleRate int
+	Channels   int
+}
+
+type VisualizerViewConfig struct {
+	Src        string `json:"src" spec:"required='true',label='Audio (WAV/PCM file, named pipe, or - for stdin)'"`
+	Mode       string `json:"mode" spec:"required='false',label='Mode (bars, wave or vu)'"`
+	Palette    string `json:"palette" spec:"required='false',label='Palette'"`
+	Encoding   string `json:"encoding" spec:"required='false',label='Raw PCM Encoding (e.g. s16le)'"`
+	SampleRate int    `json:"sample-rate" spec:"required='false',label='Raw PCM Sample Rate'"`
+	Channels   int    `json:"channels" spec:"required='false',label='Raw PCM Channels'"`
+}
+
+func VisualizerViewCreate(viewConfig c.ViewConfig) (c.View, error) {
+	config, ok := viewConfig.(*VisualizerViewConfig)
+	if !ok {
+		return nil, errors.New("Error asserting type VisualizerViewConfig")
+	}
+
+	if err := c.ValidateViewConfig(config); err != nil {
+		return nil, err
+	}
+
+	return &VisualizerView{
+		Src:        config.Src,
+		Mode:       config.Mode,
+		Palette:    config.Palette,
+		Encoding:   config.Encoding,
+		SampleRate: config.SampleRate,
+		Channels:   config.Channels,
+	}, nil
+}
+
+func (v *VisualizerView) TemplateData() map[string]interface{} {
+	return map[string]interface{}{
+		"Src":        v.Src,
+		"Mode":       v.Mode,
+		"Palette":    v.Palette,
+		"Encoding":   v.Encoding,
+		"SampleRate": v.SampleRate,
+		"Channels":   v.Channels,
+	}
+}
+
+func (v *VisualizerView) TemplateString() string {
+	return `
+		<template size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}">
+			<visualizer size-x="{{ $MatrixSizex }}" size-y="{{ $MatrixSizey }}" src="{{ .Src }}" mode="{{ .Mode }}" palette="{{ .Palette }}"
+				encoding="{{ .Encoding }}" sample-rate="{{ .SampleRate }}" channels="{{ .Channels }}"></visualizer>
+		 </template>
+	`
+}
+
+func init() {
+	c.RegisterView("visualizer", c.RegisteredView{
+		NewConfig: func() c.ViewConfig { return &VisualizerViewConfig{} },
+		NewView:   VisualizerViewCreate,
+	})
+}